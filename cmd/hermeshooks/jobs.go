@@ -0,0 +1,544 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/gosom/hermeshooks/pkg/client"
+)
+
+// jobsConfig is the content of the config file of the jobs command.
+// The flags and the environment variables take precedence over it.
+type jobsConfig struct {
+	Server string `json:"server"`
+	ApiKey string `json:"apiKey"`
+}
+
+func defaultJobsConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "hermeshooks", "config.json")
+}
+
+// withJobsFlags adds the connection and output flags to the flags of a
+// jobs subcommand
+func withJobsFlags(flags ...cli.Flag) []cli.Flag {
+	return append(flags,
+		&cli.StringFlag{
+			Name:    "server",
+			Usage:   "the url of the hermeshooks server",
+			EnvVars: []string{"HERMESHOOKS_SERVER"},
+			Value:   "http://localhost:8000",
+		},
+		&cli.StringFlag{
+			Name:    "api-key",
+			Usage:   "the api key of the account",
+			EnvVars: []string{"HERMESHOOKS_API_KEY"},
+		},
+		&cli.StringFlag{
+			Name:    "config",
+			Usage:   "a JSON file with the server and the apiKey",
+			EnvVars: []string{"HERMESHOOKS_CONFIG"},
+			Value:   defaultJobsConfigFile(),
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "prints JSON instead of tables",
+		},
+	)
+}
+
+func newJobsClient(c *cli.Context) (*client.Client, error) {
+	var cfg jobsConfig
+	path := c.String("config")
+	if len(path) > 0 {
+		b, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(b, &cfg); err != nil {
+				return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+		case !errors.Is(err, fs.ErrNotExist) || c.IsSet("config"):
+			return nil, err
+		}
+	}
+	if c.IsSet("server") || len(cfg.Server) == 0 {
+		cfg.Server = c.String("server")
+	}
+	if c.IsSet("api-key") {
+		cfg.ApiKey = c.String("api-key")
+	}
+	if len(cfg.ApiKey) == 0 {
+		return nil, errors.New("an api key is required, use --api-key, HERMESHOOKS_API_KEY or the config file")
+	}
+	return client.New(cfg.Server, client.WithAPIKey(cfg.ApiKey)), nil
+}
+
+func jobsTask(ctx context.Context) *cli.Command {
+	cmd := cli.Command{
+		Name:  "jobs",
+		Usage: "manages the scheduled jobs of an account through the REST API",
+		Subcommands: []*cli.Command{
+			jobsCreateTask(ctx),
+			jobsGetTask(ctx),
+			jobsListTask(ctx),
+			jobsCancelTask(ctx),
+			jobsRetryTask(ctx),
+			jobsTailTask(ctx),
+		},
+	}
+	return &cmd
+}
+
+// ============================================================================
+
+func jobsCreateTask(ctx context.Context) *cli.Command {
+	cmd := cli.Command{
+		Name:  "create",
+		Usage: "schedules a job from a JSON file and/or flags",
+		Flags: withJobsFlags(
+			&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Usage: "a JSON job like the body of POST /scheduledJobs, - reads stdin"},
+			&cli.StringFlag{Name: "name"},
+			&cli.StringFlag{Name: "description"},
+			&cli.StringFlag{Name: "url", Usage: "the url of the webhook"},
+			&cli.StringFlag{Name: "payload"},
+			&cli.StringFlag{Name: "content-type"},
+			&cli.StringFlag{Name: "signature"},
+			&cli.StringFlag{Name: "method"},
+			&cli.StringSliceFlag{Name: "header", Usage: "a request header as Name:value"},
+			&cli.StringSliceFlag{Name: "query", Usage: "a query parameter as name=value"},
+			&cli.StringFlag{Name: "run-at", Usage: "an RFC3339 time"},
+			&cli.DurationFlag{Name: "in", Usage: "runs the job after the duration instead of at --run-at"},
+			&cli.IntFlag{Name: "retries"},
+			&cli.StringFlag{Name: "schedule", Usage: "a cron expression"},
+			&cli.StringFlag{Name: "timezone"},
+			&cli.StringFlag{Name: "interval"},
+			&cli.IntFlag{Name: "max-runs"},
+			&cli.StringSliceFlag{Name: "tag"},
+			&cli.StringFlag{Name: "dedup-key"},
+			&cli.StringFlag{Name: "dedup-policy"},
+		),
+		Action: func(c *cli.Context) error {
+			cl, err := newJobsClient(c)
+			if err != nil {
+				return err
+			}
+			p, err := createPayloadFromFlags(c)
+			if err != nil {
+				return err
+			}
+			created, err := cl.CreateScheduledJob(ctx, p)
+			if err != nil {
+				return err
+			}
+			if c.Bool("json") {
+				return printJSON(c.App.Writer, created)
+			}
+			fmt.Fprintln(c.App.Writer, created.UUID)
+			return nil
+		},
+	}
+	return &cmd
+}
+
+func createPayloadFromFlags(c *cli.Context) (client.ScheduledJobsPayload, error) {
+	var p client.ScheduledJobsPayload
+	if path := c.String("file"); len(path) > 0 {
+		var (
+			b   []byte
+			err error
+		)
+		if path == "-" {
+			b, err = io.ReadAll(c.App.Reader)
+		} else {
+			b, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return p, err
+		}
+		if err := json.Unmarshal(b, &p); err != nil {
+			return p, fmt.Errorf("invalid job file: %w", err)
+		}
+	}
+	strs := map[string]*string{
+		"name":         &p.Name,
+		"description":  &p.Description,
+		"url":          &p.Url,
+		"payload":      &p.Payload,
+		"content-type": &p.ContentType,
+		"signature":    &p.Signature,
+		"method":       &p.Method,
+		"schedule":     &p.Schedule,
+		"timezone":     &p.Timezone,
+		"interval":     &p.Interval,
+		"dedup-key":    &p.DedupKey,
+		"dedup-policy": &p.DedupPolicy,
+	}
+	for name, v := range strs {
+		if c.IsSet(name) {
+			*v = c.String(name)
+		}
+	}
+	if c.IsSet("retries") {
+		p.Retries = c.Int("retries")
+	}
+	if c.IsSet("max-runs") {
+		p.MaxRuns = c.Int("max-runs")
+	}
+	if c.IsSet("tag") {
+		p.Tags = c.StringSlice("tag")
+	}
+	if c.IsSet("run-at") {
+		runAt, err := time.Parse(time.RFC3339, c.String("run-at"))
+		if err != nil {
+			return p, fmt.Errorf("invalid --run-at: %w", err)
+		}
+		p.RunAt = runAt
+	}
+	if c.IsSet("in") {
+		p.RunAt = time.Now().UTC().Add(c.Duration("in"))
+	}
+	if c.IsSet("header") {
+		headers, err := parsePairs(c.StringSlice("header"), ":")
+		if err != nil {
+			return p, err
+		}
+		p.Headers = headers
+	}
+	if c.IsSet("query") {
+		query, err := parsePairs(c.StringSlice("query"), "=")
+		if err != nil {
+			return p, err
+		}
+		p.Query = query
+	}
+	return p, nil
+}
+
+func parsePairs(items []string, sep string) (map[string]string, error) {
+	ans := make(map[string]string, len(items))
+	for _, item := range items {
+		k, v, found := strings.Cut(item, sep)
+		if !found {
+			return nil, fmt.Errorf("%q must be in the form name%svalue", item, sep)
+		}
+		ans[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return ans, nil
+}
+
+// ============================================================================
+
+func jobsGetTask(ctx context.Context) *cli.Command {
+	cmd := cli.Command{
+		Name:      "get",
+		Usage:     "shows a job and its executions",
+		ArgsUsage: "<uuid>",
+		Flags:     withJobsFlags(),
+		Action: func(c *cli.Context) error {
+			uid, err := uidArg(c)
+			if err != nil {
+				return err
+			}
+			cl, err := newJobsClient(c)
+			if err != nil {
+				return err
+			}
+			job, err := cl.GetScheduledJob(ctx, uid)
+			if err != nil {
+				return err
+			}
+			if c.Bool("json") {
+				return printJSON(c.App.Writer, job)
+			}
+			return printJob(c.App.Writer, job)
+		},
+	}
+	return &cmd
+}
+
+// ============================================================================
+
+func jobsListTask(ctx context.Context) *cli.Command {
+	cmd := cli.Command{
+		Name:  "list",
+		Usage: "lists jobs",
+		Flags: withJobsFlags(
+			&cli.StringSliceFlag{Name: "status"},
+			&cli.StringSliceFlag{Name: "tag"},
+			&cli.StringFlag{Name: "name-prefix"},
+			&cli.StringFlag{Name: "url-host"},
+			&cli.IntFlag{Name: "limit", Usage: "the number of jobs per page"},
+			&cli.StringFlag{Name: "cursor", Usage: "the nextCursor of the previous page"},
+			&cli.BoolFlag{Name: "desc", Usage: "lists the jobs that run last first"},
+			&cli.BoolFlag{Name: "all", Usage: "follows the cursor until the last page"},
+		),
+		Action: func(c *cli.Context) error {
+			cl, err := newJobsClient(c)
+			if err != nil {
+				return err
+			}
+			o := client.ListOptions{
+				Filter: client.ScheduledJobsFilterPayload{
+					Status:     c.StringSlice("status"),
+					Tags:       c.StringSlice("tag"),
+					NamePrefix: c.String("name-prefix"),
+					UrlHost:    c.String("url-host"),
+				},
+				Desc:   c.Bool("desc"),
+				Limit:  c.Int("limit"),
+				Cursor: c.String("cursor"),
+			}
+			var ans client.ScheduledJobListResponse
+			for {
+				page, err := cl.ListScheduledJobs(ctx, o)
+				if err != nil {
+					return err
+				}
+				ans.Items = append(ans.Items, page.Items...)
+				ans.NextCursor = page.NextCursor
+				if !c.Bool("all") || len(page.NextCursor) == 0 {
+					break
+				}
+				o.Cursor = page.NextCursor
+			}
+			if c.Bool("json") {
+				return printJSON(c.App.Writer, ans)
+			}
+			if err := printJobs(c.App.Writer, ans.Items); err != nil {
+				return err
+			}
+			if len(ans.NextCursor) > 0 {
+				fmt.Fprintf(c.App.ErrWriter, "more jobs with --cursor %s\n", ans.NextCursor)
+			}
+			return nil
+		},
+	}
+	return &cmd
+}
+
+// ============================================================================
+
+func jobsCancelTask(ctx context.Context) *cli.Command {
+	cmd := cli.Command{
+		Name:      "cancel",
+		Usage:     "cancels jobs that have not started executing yet",
+		ArgsUsage: "<uuid>...",
+		Flags:     withJobsFlags(),
+		Action: func(c *cli.Context) error {
+			if c.NArg() == 0 {
+				return errors.New("at least one uuid is required")
+			}
+			cl, err := newJobsClient(c)
+			if err != nil {
+				return err
+			}
+			jobs := make([]client.ScheduledJobGetResponse, 0, c.NArg())
+			for _, uid := range c.Args().Slice() {
+				job, err := cl.CancelScheduledJob(ctx, uid)
+				if err != nil {
+					return fmt.Errorf("%s: %w", uid, err)
+				}
+				jobs = append(jobs, job)
+			}
+			if c.Bool("json") {
+				return printJSON(c.App.Writer, jobs)
+			}
+			return printJobs(c.App.Writer, jobs)
+		},
+	}
+	return &cmd
+}
+
+// ============================================================================
+
+func jobsRetryTask(ctx context.Context) *cli.Command {
+	cmd := cli.Command{
+		Name:      "retry",
+		Usage:     "runs a scheduled job now or sends the request of a finished job again",
+		ArgsUsage: "<uuid>",
+		Flags:     withJobsFlags(),
+		Action: func(c *cli.Context) error {
+			uid, err := uidArg(c)
+			if err != nil {
+				return err
+			}
+			cl, err := newJobsClient(c)
+			if err != nil {
+				return err
+			}
+			job, err := cl.GetScheduledJob(ctx, uid)
+			if err != nil {
+				return err
+			}
+			switch job.Status {
+			case "scheduled":
+				job, err = cl.TriggerScheduledJob(ctx, uid)
+			case "success", "fail":
+				job, err = cl.ReplayScheduledJob(ctx, uid)
+			default:
+				return fmt.Errorf("cannot retry a %s job", job.Status)
+			}
+			if err != nil {
+				return err
+			}
+			if c.Bool("json") {
+				return printJSON(c.App.Writer, job)
+			}
+			return printJob(c.App.Writer, job)
+		},
+	}
+	return &cmd
+}
+
+// ============================================================================
+
+func jobsTailTask(ctx context.Context) *cli.Command {
+	cmd := cli.Command{
+		Name:      "tail",
+		Usage:     "follows the executions of a job until it finishes",
+		ArgsUsage: "<uuid>",
+		Flags: withJobsFlags(
+			&cli.DurationFlag{Name: "interval", Usage: "how often the job is polled", Value: 5 * time.Second},
+		),
+		Action: func(c *cli.Context) error {
+			uid, err := uidArg(c)
+			if err != nil {
+				return err
+			}
+			cl, err := newJobsClient(c)
+			if err != nil {
+				return err
+			}
+			return tailJob(ctx, c, cl, uid)
+		},
+	}
+	return &cmd
+}
+
+func tailJob(ctx context.Context, c *cli.Context, cl *client.Client, uid string) error {
+	var (
+		status   string
+		lastSeen time.Time
+	)
+	ticker := time.NewTicker(c.Duration("interval"))
+	defer ticker.Stop()
+	for {
+		job, err := cl.GetScheduledJob(ctx, uid)
+		if err != nil {
+			return err
+		}
+		// executions are returned newest first
+		for i := len(job.Executions) - 1; i >= 0; i-- {
+			e := job.Executions[i]
+			if !e.ExecutedAt.After(lastSeen) {
+				continue
+			}
+			lastSeen = e.ExecutedAt
+			if c.Bool("json") {
+				if err := printJSON(c.App.Writer, e); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Fprintf(c.App.Writer, "%s\texecution\trun=%d attempt=%d status=%d error=%s latency=%dms\t%s\n",
+				e.ExecutedAt.Format(time.RFC3339), e.Run, e.Attempt, e.StatusCode, e.ErrorClass, e.LatencyMs, executionMsg(e))
+		}
+		if job.Status != status {
+			status = job.Status
+			if !c.Bool("json") {
+				fmt.Fprintf(c.App.Writer, "%s\tstatus\t%s\trunAt=%s runs=%d\n",
+					time.Now().UTC().Format(time.RFC3339), job.Status, job.RunAt.Format(time.RFC3339), job.Runs)
+			}
+		}
+		switch job.Status {
+		case "success", "fail", "deleted":
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ============================================================================
+
+func uidArg(c *cli.Context) (string, error) {
+	if c.NArg() != 1 {
+		return "", errors.New("exactly one uuid is required")
+	}
+	return c.Args().First(), nil
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printJobs(w io.Writer, jobs []client.ScheduledJobGetResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "UUID\tNAME\tSTATUS\tRUN AT\tRUNS\tMETHOD\tURL")
+	for _, job := range jobs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			job.UID, job.Name, job.Status, job.RunAt.Format(time.RFC3339), job.Runs, job.Method, job.Url)
+	}
+	return tw.Flush()
+}
+
+func printJob(w io.Writer, job client.ScheduledJobGetResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fields := [][2]string{
+		{"UUID", job.UID.String()},
+		{"NAME", job.Name},
+		{"DESCRIPTION", job.Description},
+		{"STATUS", job.Status},
+		{"METHOD", job.Method},
+		{"URL", job.Url},
+		{"CONTENT TYPE", job.ContentType},
+		{"RUN AT", job.RunAt.Format(time.RFC3339)},
+		{"RETRIES", strconv.Itoa(job.Retries)},
+		{"RUNS", strconv.Itoa(job.Runs)},
+		{"ATTEMPTS", strconv.Itoa(job.Attempts)},
+		{"SCHEDULE", job.Schedule},
+		{"INTERVAL", job.Interval},
+		{"TAGS", strings.Join(job.Tags, ",")},
+		{"DEDUP KEY", job.DedupKey},
+	}
+	for _, f := range fields {
+		if len(f[1]) > 0 {
+			fmt.Fprintf(tw, "%s\t%s\n", f[0], f[1])
+		}
+	}
+	if len(job.Executions) > 0 {
+		fmt.Fprintln(tw, "\nEXECUTED AT\tRUN\tATTEMPT\tSTATUS CODE\tERROR\tLATENCY\tMESSAGE")
+		for _, e := range job.Executions {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%dms\t%s\n",
+				e.ExecutedAt.Format(time.RFC3339), e.Run, e.Attempt, e.StatusCode, e.ErrorClass, e.LatencyMs, executionMsg(e))
+		}
+	}
+	return tw.Flush()
+}
+
+func executionMsg(e client.ExecutionResponse) string {
+	if e.RetryAfter == nil {
+		return e.Msg
+	}
+	return e.Msg + " (retry after " + e.RetryAfter.Format(time.RFC3339) + ")"
+}
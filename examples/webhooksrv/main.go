@@ -1,12 +1,14 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
+
+	"github.com/gosom/hermeshooks/pkg/client"
+	"github.com/gosom/hermeshooks/pkg/webhook"
 )
 
 const (
@@ -14,13 +16,20 @@ const (
 	NGROK_URL = "https://86bd-164-215-13-207.ngrok.io"
 	// Replace with your API KEY
 	APIKEY = "r_AUiush4KF6CX1RLiDTzcnR9ZszKAnKOXnRLF7Xl3o="
+	// Replace with the signing secret returned on signup
+	SIGNING_SECRET = "whsec_replace-me"
 	// Replace with your server url
 	ServerUrl = "http://localhost:8000"
 )
 
 func main() {
+	// only requests signed by hermeshooks reach wh
+	verifier, err := webhook.NewVerifier(webhook.WithSecrets(SIGNING_SECRET))
+	if err != nil {
+		panic(err)
+	}
 	http.HandleFunc("/", schedule)
-	http.HandleFunc("/webhook", wh)
+	http.Handle("/webhook", verifier.Middleware(http.HandlerFunc(wh)))
 
 	if err := http.ListenAndServe("127.0.0.1:8090", nil); err != nil {
 		panic(err)
@@ -34,57 +43,28 @@ func schedule(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	runAt := time.Now().UTC().Add(time.Minute * 5)
-	postBody, err := json.Marshal(map[string]interface{}{
-		"name":        "hello-world",    // a descriptive name
-		"description": "just to say hi", // a small description
-		"url":         NGROK_URL + "/webhook",
-		"payload":     `{"msg": "hi there"}`,      // the payload to receive. Notice, it should be  a string
-		"contentType": "application/json",         // the content  type
-		"signature":   "",                         // TODO
-		"runAt":       runAt.Format(time.RFC3339), // when to happen
-		"retries":     1,                          // how many times to retry
+	c := client.New(ServerUrl, client.WithAPIKey(APIKEY))
+	_, err := c.CreateScheduledJob(req.Context(), client.ScheduledJobsPayload{
+		Name:        "hello-world",    // a descriptive name
+		Description: "just to say hi", // a small description
+		Url:         NGROK_URL + "/webhook",
+		Payload:     `{"msg": "hi there"}`,                 // the payload to receive. Notice, it should be  a string
+		ContentType: "application/json",                    // the content  type
+		RunAt:       time.Now().UTC().Add(5 * time.Minute), // when to happen
+		Retries:     1,                                     // how many times to retry
 	})
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
+	var apiErr *client.Error
+	switch {
+	case errors.As(err, &apiErr):
+		w.WriteHeader(apiErr.StatusCode)
+		w.Write([]byte(apiErr.Message))
 		return
-	}
-
-	r, err := http.NewRequestWithContext(
-		req.Context(),
-		http.MethodPost,
-		ServerUrl+"/api/v1/scheduledJobs",
-		bytes.NewBuffer(postBody),
-	)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
-		return
-	}
-
-	r.Header.Set("Content-Type", "application/json")
-	r.Header.Set("X-API-KEY", APIKEY)
-
-	resp, err := http.DefaultClient.Do(r)
-
-	if err != nil {
+	case err != nil:
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(err.Error()))
 		return
 	}
-
-	defer func() {
-		io.Copy(io.Discard, resp.Body)
-		resp.Body.Close()
-	}()
-
-	if resp.StatusCode != http.StatusCreated {
-		w.WriteHeader(resp.StatusCode)
-		return
-	}
 	w.WriteHeader(http.StatusCreated)
-
 }
 
 func wh(w http.ResponseWriter, req *http.Request) {
@@ -92,18 +72,15 @@ func wh(w http.ResponseWriter, req *http.Request) {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	body, err := io.ReadAll(req.Body)
-	if err != nil {
-		panic(err)
-	}
+	delivery, _ := webhook.FromContext(req.Context())
 	var p map[string]string
-	if err := json.Unmarshal(body, &p); err != nil {
-		panic(err)
-	}
-
-	for k, v := range req.Header {
-		fmt.Println(k, v[0]) // print the headers
+	if err := json.Unmarshal(delivery.Body, &p); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
 
+	// the attempt and the scheduled time are not signed, only log them
+	meta := webhook.ParseMetadata(req.Header)
+	fmt.Println("job", delivery.JobUID, "attempt", meta.Attempt, "scheduled at", meta.ScheduledAt)
 	fmt.Println(p["msg"]) // it should print hi there
 }
@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gosom/hermeshooks/internal/entities"
@@ -41,6 +43,14 @@ func NewLogger(debug bool) zerolog.Logger {
 	return logger
 }
 
+// maxRetryDoWait is the longest Retry-After that RetryDo waits for
+const maxRetryDoWait = time.Minute
+
+// RetryDo sends the request up to maxRetries times with an exponential
+// backoff until it gets a response with a status code below 500 other than
+// 429. A Retry-After header longer than the backoff is waited instead, and
+// when it is longer than a minute the response is returned to the caller.
+// Waiting between attempts stops when the context of the request is done.
 func RetryDo(client HTTPClient, req *http.Request, maxRetries int) (*http.Response, error) {
 	var (
 		body []byte
@@ -65,20 +75,77 @@ func RetryDo(client HTTPClient, req *http.Request, maxRetries int) (*http.Respon
 			req.Body = io.NopCloser(bytes.NewReader(body))
 		}
 		resp, err = client.Do(req)
-		if err == nil && resp.StatusCode < 500 {
+		if err == nil && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
 			return resp, nil
 		}
+		// the response of the last attempt is returned to the caller
+		if i == maxRetries {
+			break
+		}
+		wait := backoff(i)
+		if d, ok := RetryAfter(resp, time.Now()); ok {
+			if d > maxRetryDoWait {
+				break
+			}
+			if d > wait {
+				wait = d
+			}
+		}
 		if resp != nil {
 			io.Copy(io.Discard, resp.Body)
 			resp.Body.Close()
 		}
-		if i != maxRetries {
-			time.Sleep(backoff(i))
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
 		}
 	}
 	return resp, err
 }
 
+// maxRetryAfter caps the wait that a Retry-After header can ask for
+const maxRetryAfter = 7 * 24 * time.Hour
+
+// RetryAfter returns how long a 429 or 503 response asks the client to wait
+// before the next request. The Retry-After header is given in seconds or as
+// an HTTP date.
+func RetryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if len(v) == 0 {
+		return 0, false
+	}
+	var d time.Duration
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(maxRetryAfter/time.Second) {
+			return maxRetryAfter, true
+		}
+		d = time.Duration(seconds) * time.Second
+	} else {
+		t, err := http.ParseTime(v)
+		if err != nil {
+			return 0, false
+		}
+		d = t.Sub(now)
+	}
+	switch {
+	case d < 0:
+		d = 0
+	case d > maxRetryAfter:
+		d = maxRetryAfter
+	}
+	return d, true
+}
+
 func ExpSquaring(x, n int) int {
 	if n < 0 {
 		x = 1 / x
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"seconds", http.StatusTooManyRequests, "120", 2 * time.Minute, true},
+		{"service unavailable", http.StatusServiceUnavailable, "5", 5 * time.Second, true},
+		{"http date", http.StatusTooManyRequests, now.Add(time.Hour).Format(http.TimeFormat), time.Hour, true},
+		{"date in the past", http.StatusTooManyRequests, now.Add(-time.Hour).Format(http.TimeFormat), 0, true},
+		{"capped", http.StatusTooManyRequests, "99999999", maxRetryAfter, true},
+		{"capped date", http.StatusTooManyRequests, now.Add(30 * 24 * time.Hour).Format(http.TimeFormat), maxRetryAfter, true},
+		{"negative", http.StatusTooManyRequests, "-1", 0, false},
+		{"invalid", http.StatusTooManyRequests, "soon", 0, false},
+		{"missing", http.StatusTooManyRequests, "", 0, false},
+		{"other status", http.StatusInternalServerError, "120", 0, false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		if len(tt.header) > 0 {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		got, ok := RetryAfter(resp, now)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("%s: RetryAfter = %s, %v, want %s, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+	if _, ok := RetryAfter(nil, now); ok {
+		t.Error("RetryAfter(nil) reported a wait")
+	}
+}
@@ -1,16 +1,22 @@
 package cryptoutils
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	hashCost   = bcrypt.DefaultCost
-	apiKeySize = 32
+	hashCost          = bcrypt.DefaultCost
+	apiKeySize        = 32
+	signingSecretSize = 32
+
+	// SigningSecretPrefix makes signing secrets easy to tell apart from api keys
+	SigningSecretPrefix = "whsec_"
 )
 
 func HashPassword(password string) ([]byte, error) {
@@ -35,6 +41,21 @@ func XApiKey() string {
 	return base64.URLEncoding.EncodeToString(b)
 }
 
+func SigningSecret() string {
+	b, err := RandomBytes(signingSecretSize)
+	if err != nil {
+		panic(err)
+	}
+	return SigningSecretPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// HmacSha256 returns the hex encoded HMAC-SHA256 of msg
+func HmacSha256(secret string, msg []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(msg)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func RandomBytes(n int) ([]byte, error) {
 	b := make([]byte, n)
 	_, err := rand.Read(b)
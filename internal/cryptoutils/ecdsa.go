@@ -0,0 +1,126 @@
+package cryptoutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// LoadECDSAKey reads a PEM encoded ECDSA private key from path
+func LoadECDSAKey(path string) (*ecdsa.PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseECDSAPrivateKey(b)
+}
+
+// LoadOrGenerateECDSAKey reads a PEM encoded ECDSA private key from path.
+// When the file does not exist a P-256 key is generated and written to it.
+// The file is created exclusively so concurrent starts end up with one key.
+func LoadOrGenerateECDSAKey(path string) (*ecdsa.PrivateKey, error) {
+	key, err := LoadECDSAKey(path)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return key, err
+	}
+	key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return LoadECDSAKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := pem.Encode(f, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return key, f.Close()
+}
+
+// ParseECDSAPrivateKey parses a PEM encoded ECDSA private key in either the
+// SEC 1 or the PKCS #8 format.
+func ParseECDSAPrivateKey(b []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ans, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an ECDSA private key")
+	}
+	return ans, nil
+}
+
+// ParseECDSAPublicKeys parses all the PEM encoded ECDSA public keys in b
+func ParseECDSAPublicKeys(b []byte) ([]*ecdsa.PublicKey, error) {
+	var ans []*ecdsa.PublicKey
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, errors.New("not an ECDSA public key")
+		}
+		ans = append(ans, pub)
+	}
+	return ans, nil
+}
+
+// MarshalECDSAPublicKey returns the PEM encoding of the public key
+func MarshalECDSAPublicKey(pub *ecdsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// KeyID identifies a public key by the hash of its DER encoding
+func KeyID(pub *ecdsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(hash[:12]), nil
+}
+
+// SignECDSA returns the base64 encoded ASN.1 ECDSA signature of the
+// SHA-256 hash of msg
+func SignECDSA(key *ecdsa.PrivateKey, msg []byte) (string, error) {
+	hash := sha256.Sum256(msg)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
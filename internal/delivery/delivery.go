@@ -0,0 +1,104 @@
+// Package delivery builds, signs and sends the webhook requests of jobs.
+// The workers use it for the scheduled deliveries and the server for the
+// test deliveries, so both send the same requests.
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosom/hermeshooks/internal/cryptoutils"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+// NewRequest builds the request of the job without the signatures
+func NewRequest(ctx context.Context, job entities.ScheduledJob) (*http.Request, error) {
+	var body io.Reader
+	if len(job.Payload) > 0 {
+		body = bytes.NewReader([]byte(job.Payload))
+	}
+	method := job.Method
+	if len(method) == 0 {
+		method = http.MethodPost
+	}
+	u, err := url.Parse(job.Url)
+	if err != nil {
+		return nil, err
+	}
+	if len(job.Query) > 0 {
+		q := u.Query()
+		for k, v := range job.Query {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range job.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", job.ContentType)
+	req.Header.Set("X-HERMESHOOKS-PAYLOAD-SIG", job.Signature)
+	req.Header.Set("X-HERMESHOOKS-JOB-ID", job.UID.String())
+	req.Header.Set("X-HERMESHOOKS-SCHEDULED-AT", job.RunScheduledAt.UTC().Format(time.RFC3339))
+	return req, nil
+}
+
+// Signer signs the requests with the signing secrets of the users and,
+// when it has a key, with the ECDSA key of the service
+type Signer struct {
+	key   *ecdsa.PrivateKey
+	keyID string
+}
+
+// NewSigner returns a signer that uses the key, which can be nil
+func NewSigner(key *ecdsa.PrivateKey) (Signer, error) {
+	ans := Signer{key: key}
+	if key == nil {
+		return ans, nil
+	}
+	var err error
+	ans.keyID, err = cryptoutils.KeyID(&key.PublicKey)
+	return ans, err
+}
+
+// Sign sets the X-HERMESHOOKS-TIMESTAMP header and signs
+// timestamp + "." + job id + "." + body, with the job id of the
+// X-HERMESHOOKS-JOB-ID header. The X-HERMESHOOKS-SIGNATURE header holds one
+// v1=<hex> HMAC-SHA256 entry per active signing secret of the user and the
+// X-HERMESHOOKS-SIG header holds the ECDSA signature of the service, whose
+// public key is published on /meta under X-HERMESHOOKS-KEY-ID.
+func (s Signer) Sign(req *http.Request, u entities.User, body []byte, now time.Time) error {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	msg := append([]byte(ts+"."+req.Header.Get("X-HERMESHOOKS-JOB-ID")+"."), body...)
+	req.Header.Set("X-HERMESHOOKS-TIMESTAMP", ts)
+	var signatures []string
+	for _, secret := range u.SigningSecrets(now) {
+		if len(secret) == 0 {
+			continue
+		}
+		signatures = append(signatures, "v1="+cryptoutils.HmacSha256(secret, msg))
+	}
+	if len(signatures) > 0 {
+		req.Header.Set("X-HERMESHOOKS-SIGNATURE", strings.Join(signatures, ","))
+	}
+	if s.key == nil {
+		return nil
+	}
+	sig, err := cryptoutils.SignECDSA(s.key, msg)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-HERMESHOOKS-SIG", sig)
+	req.Header.Set("X-HERMESHOOKS-KEY-ID", s.keyID)
+	return nil
+}
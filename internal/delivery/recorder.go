@@ -0,0 +1,175 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+const (
+	// maxAssertedBody is the size of the response bodies that are checked
+	// by the body assertions of a job
+	maxAssertedBody = 1 << 20
+	// maxResponseHeaders is the number of response headers that are stored
+	maxResponseHeaders = 50
+	// maxResponseHeaderValue is the size a stored header value is truncated to
+	maxResponseHeaderValue = 1024
+)
+
+// Recorder sends one attempt with the X-HERMESHOOKS-ATTEMPT header and
+// records its outcome with the headers and the start of the body of its
+// response and the Retry-After it asks for
+type Recorder struct {
+	Client common.HTTPClient
+	// BodyLimit is the number of bytes of the response body that are
+	// recorded, 0 records none
+	BodyLimit int64
+	Criteria  entities.SuccessCriteria
+	// Attempt holds the number of the attempt before it is sent and its
+	// outcome after
+	Attempt entities.Execution
+	Sent    bool
+}
+
+// Send sends the attempt and checks the response with the success criteria.
+// It returns the reason the attempt failed and whether it can be retried.
+func (c *Recorder) Send(req *http.Request) (bool, error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("request fail with error: %w", err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if !c.Criteria.IsSuccess(resp.StatusCode) {
+		return c.Criteria.IsRetryable(resp.StatusCode), fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if len(c.Criteria.Assertions) == 0 {
+		return false, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAssertedBody))
+	if err != nil {
+		err = fmt.Errorf("cannot read the body: %w", err)
+	} else {
+		err = c.Criteria.CheckBody(body)
+	}
+	if err != nil {
+		c.Attempt.ErrorClass = entities.ErrorClassAssertion
+		c.Attempt.Msg = err.Error()
+	}
+	return false, err
+}
+
+func (c *Recorder) Do(req *http.Request) (*http.Response, error) {
+	attempt := &c.Attempt
+	attempt.StartedAt = time.Now().UTC()
+	c.Sent = true
+	req.Header.Set("X-HERMESHOOKS-ATTEMPT", strconv.Itoa(attempt.Attempt))
+	resp, err := c.Client.Do(req)
+	if resp != nil {
+		attempt.StatusCode = resp.StatusCode
+		attempt.ResponseHeaders = responseHeaders(resp.Header)
+		if c.BodyLimit > 0 {
+			c.captureBody(resp, attempt)
+		}
+	}
+	attempt.FinishedAt = time.Now().UTC()
+	if d, ok := common.RetryAfter(resp, attempt.FinishedAt); ok {
+		attempt.RetryAfter = attempt.FinishedAt.Add(d)
+	}
+	attempt.CreatedAt = attempt.FinishedAt
+	switch {
+	case err != nil:
+		attempt.Msg = err.Error()
+	case !c.Criteria.IsSuccess(attempt.StatusCode):
+		attempt.Msg = fmt.Sprintf("unexpected status code %d", attempt.StatusCode)
+	}
+	attempt.ErrorClass = classifyError(err, attempt.StatusCode, c.Criteria)
+	return resp, err
+}
+
+// captureBody stores up to BodyLimit bytes of the body in the attempt.
+// The bytes that are read are put back so the caller sees the whole body.
+func (c *Recorder) captureBody(resp *http.Response, attempt *entities.Execution) {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, c.BodyLimit+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), resp.Body), resp.Body}
+	if int64(len(b)) > c.BodyLimit {
+		b = b[:c.BodyLimit]
+		attempt.ResponseTruncated = true
+	}
+	// the body is stored as text
+	body := strings.ReplaceAll(string(b), "\x00", "")
+	attempt.ResponseBody = strings.ToValidUTF8(body, "\uFFFD")
+}
+
+func responseHeaders(h http.Header) map[string][]string {
+	ans := make(map[string][]string, len(h))
+	for k, values := range h {
+		if k == "Set-Cookie" {
+			continue
+		}
+		if len(ans) == maxResponseHeaders {
+			break
+		}
+		for _, v := range values {
+			if len(v) > maxResponseHeaderValue {
+				v = v[:maxResponseHeaderValue]
+			}
+			ans[k] = append(ans[k], v)
+		}
+	}
+	return ans
+}
+
+func classifyError(err error, statusCode int, criteria entities.SuccessCriteria) entities.ErrorClass {
+	if err == nil {
+		switch {
+		case criteria.IsSuccess(statusCode):
+			return entities.ErrorClassNone
+		case statusCode >= 500:
+			return entities.ErrorClassServerError
+		case statusCode >= 400:
+			return entities.ErrorClassClientError
+		}
+		return entities.ErrorClassUnexpectedStatus
+	}
+	var (
+		netErr         net.Error
+		dnsErr         *net.DNSError
+		opErr          *net.OpError
+		recordErr      tls.RecordHeaderError
+		unknownAuthErr x509.UnknownAuthorityError
+		hostnameErr    x509.HostnameError
+		invalidCertErr x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return entities.ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return entities.ErrorClassTimeout
+	case errors.As(err, &dnsErr):
+		return entities.ErrorClassDNS
+	case errors.As(err, &recordErr), errors.As(err, &unknownAuthErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidCertErr):
+		return entities.ErrorClassTLS
+	case errors.As(err, &opErr):
+		return entities.ErrorClassConnection
+	}
+	return entities.ErrorClassOther
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BulkOperationStatus int
+
+const (
+	OperationUndefined BulkOperationStatus = iota
+	OperationRunning
+	OperationDone
+	OperationFailed
+)
+
+func (s BulkOperationStatus) String() string {
+	switch s {
+	case OperationUndefined:
+		return "undefined"
+	case OperationRunning:
+		return "running"
+	case OperationDone:
+		return "done"
+	case OperationFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// BulkOperation tracks a change to many jobs that runs in the background
+type BulkOperation struct {
+	ID        int64
+	UID       uuid.UUID
+	UserID    int64
+	Kind      string
+	Status    BulkOperationStatus
+	Affected  int64
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
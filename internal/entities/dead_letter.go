@@ -0,0 +1,33 @@
+package entities
+
+import "time"
+
+// DeadLetter is a job whose last run failed and that is not scheduled to run
+// again. A redrive starts a new run of it.
+type DeadLetter struct {
+	Job ScheduledJob
+	// Executions are the attempts of the last run, the latest first
+	Executions []Execution
+}
+
+// Redrive sets how dead letters are delivered again
+type Redrive struct {
+	// Url replaces the url of the job when set
+	Url string
+	// Delay is the time from the redrive to the first attempt
+	Delay time.Duration
+}
+
+// Apply schedules the first attempt of a new run of the job. The executions
+// of the previous runs are kept.
+func (r Redrive) Apply(job ScheduledJob, now time.Time) ScheduledJob {
+	if len(r.Url) > 0 {
+		job.Url = r.Url
+	}
+	job.Status = Scheduled
+	job.RunAt = now.Add(r.Delay)
+	job.Attempts = 0
+	job.RunScheduledAt = time.Time{}
+	job.UpdatedAt = now
+	return job
+}
@@ -0,0 +1,34 @@
+package entities
+
+import "errors"
+
+var (
+	// ErrJobNotFound is returned when a job does not exist or belongs to another user
+	ErrJobNotFound = errors.New("scheduled job not found")
+	// ErrJobNotScheduled is returned when a job cannot be changed because it
+	// is not in the scheduled status
+	ErrJobNotScheduled = errors.New("scheduled job is not in scheduled status")
+	// ErrJobNotPaused is returned when a job cannot be resumed because it is
+	// not in the paused status
+	ErrJobNotPaused = errors.New("scheduled job is not in paused status")
+	// ErrJobNotFailed is returned when a job cannot be redriven because it is
+	// not in the fail status
+	ErrJobNotFailed = errors.New("scheduled job is not in fail status")
+	// ErrJobNotFinished is returned when a job cannot be replayed because it
+	// is not in the success or fail status
+	ErrJobNotFinished = errors.New("scheduled job is not in success or fail status")
+	// ErrDuplicateJob is returned when a job with the same dedup key exists
+	ErrDuplicateJob = errors.New("a job with the same dedup key is already scheduled")
+	// ErrIdempotencyKeyMismatch is returned when an idempotency key is reused
+	// with a different request
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was used with a different request")
+	// ErrOperationNotFound is returned when a bulk operation does not exist
+	// or belongs to another user
+	ErrOperationNotFound = errors.New("bulk operation not found")
+	// ErrOperationRunning is returned when a bulk operation is started while
+	// another one of the user is running
+	ErrOperationRunning = errors.New("another bulk operation is running")
+	// ErrRateLimited is returned when a user makes too many requests of a
+	// kind in a short time
+	ErrRateLimited = errors.New("too many requests, try again later")
+)
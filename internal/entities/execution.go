@@ -2,10 +2,102 @@ package entities
 
 import "time"
 
+// Execution is one HTTP attempt of a run of a job
 type Execution struct {
 	ID             int64
 	ScheduledJobID int64
-	StatusCode     int
-	Msg            string
-	CreatedAt      time.Time
+	// Run is the value of the job's Runs after the run
+	Run int
+	// Attempt starts from 1 for every run. It is 0 when no request was sent.
+	Attempt    int
+	StatusCode int
+	Msg        string
+	ErrorClass ErrorClass
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Worker is the name of the worker that sent the request
+	Worker    string
+	Partition int
+	// ResponseHeaders and ResponseBody are cleared after the retention period.
+	// ResponseBody holds only the start of the body when ResponseTruncated.
+	ResponseHeaders   map[string][]string
+	ResponseBody      string
+	ResponseTruncated bool
+	CreatedAt         time.Time
+
+	// RetryAfter is the earliest time of the next attempt that a 429 or 503
+	// response asked for with a Retry-After header
+	RetryAfter time.Time
+}
+
+func (e Execution) Latency() time.Duration {
+	if e.StartedAt.IsZero() || e.FinishedAt.IsZero() {
+		return 0
+	}
+	return e.FinishedAt.Sub(e.StartedAt)
+}
+
+// TestDelivery is a request of a job that was sent right away without
+// storing the job
+type TestDelivery struct {
+	Method  string
+	Url     string
+	Headers map[string][]string
+	Body    string
+	// Execution is the outcome of the request
+	Execution Execution
+}
+
+// ErrorClass groups the reasons an attempt failed
+type ErrorClass int
+
+const (
+	ErrorClassNone ErrorClass = iota
+	// ErrorClassRequest means the request could not be built
+	ErrorClassRequest
+	ErrorClassDNS
+	ErrorClassConnection
+	ErrorClassTLS
+	ErrorClassTimeout
+	ErrorClassCanceled
+	// ErrorClassClientError is a response with a 4xx status code that is not
+	// a success code of the job
+	ErrorClassClientError
+	// ErrorClassServerError is a response with a 5xx status code that is not
+	// a success code of the job
+	ErrorClassServerError
+	ErrorClassOther
+	// ErrorClassUnexpectedStatus is a response with a status code below 400
+	// that is not a success code of the job
+	ErrorClassUnexpectedStatus
+	// ErrorClassAssertion is a response whose body failed an assertion
+	ErrorClassAssertion
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassNone:
+		return ""
+	case ErrorClassRequest:
+		return "request"
+	case ErrorClassDNS:
+		return "dns"
+	case ErrorClassConnection:
+		return "connection"
+	case ErrorClassTLS:
+		return "tls"
+	case ErrorClassTimeout:
+		return "timeout"
+	case ErrorClassCanceled:
+		return "canceled"
+	case ErrorClassClientError:
+		return "client_error"
+	case ErrorClassServerError:
+		return "server_error"
+	case ErrorClassUnexpectedStatus:
+		return "unexpected_status"
+	case ErrorClassAssertion:
+		return "assertion"
+	}
+	return "other"
 }
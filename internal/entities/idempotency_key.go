@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// IdempotencyKey remembers the response of a request so that retries with
+// the same key get the same response instead of creating duplicates.
+type IdempotencyKey struct {
+	ID          int64
+	UserID      int64
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Response    string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy decides when a failed attempt of a run is retried. Every
+// retry reschedules the job, so waiting for it does not hold a worker.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a run including the first
+	MaxAttempts  int
+	InitialDelay time.Duration
+	// Multiplier grows the delay after every attempt up to MaxDelay
+	Multiplier float64
+	MaxDelay   time.Duration
+	// Jitter is the largest fraction of a delay that is randomly removed
+	// from it
+	Jitter float64
+	// MaxAge is how long after its scheduled time a run can be retried
+	MaxAge time.Duration
+}
+
+// DefaultRetryPolicy is used by jobs without a retry policy, with the
+// retries of the job as MaxAttempts
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  1,
+	InitialDelay: 30 * time.Second,
+	Multiplier:   2,
+	MaxDelay:     time.Hour,
+	Jitter:       0.2,
+	MaxAge:       24 * time.Hour,
+}
+
+// Delay returns the time to wait after the failed attempt, which starts
+// from 1, without the jitter
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	if d > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(d)
+}
+
+// NextAttempt returns when the attempt after the failed one runs. The
+// destination can ask for a later attempt with retryAfter, which is zero
+// otherwise. It returns false when the run has no attempts left or the next
+// one would be later than MaxAge after scheduledAt.
+func (p RetryPolicy) NextAttempt(attempt int, scheduledAt, now, retryAfter time.Time) (time.Time, bool) {
+	if attempt >= p.MaxAttempts {
+		return time.Time{}, false
+	}
+	d := p.Delay(attempt)
+	d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	next := now.Add(d)
+	if retryAfter.After(next) {
+		next = retryAfter
+	}
+	if p.MaxAge > 0 && next.After(scheduledAt.Add(p.MaxAge)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Retry returns the retry policy of the job
+func (j ScheduledJob) Retry() RetryPolicy {
+	if j.RetryPolicy.MaxAttempts > 0 {
+		return j.RetryPolicy
+	}
+	ans := DefaultRetryPolicy
+	if j.Retries > ans.MaxAttempts {
+		ans.MaxAttempts = j.Retries
+	}
+	return ans
+}
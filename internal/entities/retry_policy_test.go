@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{InitialDelay: 30 * time.Second, Multiplier: 2, MaxDelay: 5 * time.Minute}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 5 * time.Minute},
+		{20, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := p.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyNextAttempt(t *testing.T) {
+	scheduled := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	p := RetryPolicy{
+		MaxAttempts:  4,
+		InitialDelay: time.Minute,
+		Multiplier:   2,
+		MaxDelay:     time.Hour,
+		MaxAge:       time.Hour,
+	}
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		attempt    int
+		now        time.Time
+		retryAfter time.Time
+		want       time.Time
+		wantOk     bool
+	}{
+		{"first retry", p, 1, scheduled, time.Time{}, scheduled.Add(time.Minute), true},
+		{"backoff grows", p, 3, scheduled.Add(10 * time.Minute), time.Time{}, scheduled.Add(14 * time.Minute), true},
+		{"no attempts left", p, 4, scheduled, time.Time{}, time.Time{}, false},
+		{"past max age", p, 3, scheduled.Add(58 * time.Minute), time.Time{}, time.Time{}, false},
+		{"at max age", p, 1, scheduled.Add(59 * time.Minute), time.Time{}, scheduled.Add(time.Hour), true},
+		{"retry after is later", p, 1, scheduled, scheduled.Add(10 * time.Minute), scheduled.Add(10 * time.Minute), true},
+		{"retry after is earlier", p, 1, scheduled, scheduled.Add(10 * time.Second), scheduled.Add(time.Minute), true},
+		{"retry after past max age", p, 1, scheduled, scheduled.Add(2 * time.Hour), time.Time{}, false},
+		{"no max age", RetryPolicy{MaxAttempts: 2, InitialDelay: time.Minute, Multiplier: 1, MaxDelay: time.Minute},
+			1, scheduled.Add(48 * time.Hour), time.Time{}, scheduled.Add(48*time.Hour + time.Minute), true},
+	}
+	for _, tt := range tests {
+		got, ok := tt.policy.NextAttempt(tt.attempt, scheduled, tt.now, tt.retryAfter)
+		if ok != tt.wantOk || !got.Equal(tt.want) {
+			t.Errorf("%s: NextAttempt = %s, %v, want %s, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	p := RetryPolicy{MaxAttempts: 2, InitialDelay: 100 * time.Second, Multiplier: 1, MaxDelay: time.Hour, Jitter: 0.2}
+	for i := 0; i < 1000; i++ {
+		next, ok := p.NextAttempt(1, now, now, time.Time{})
+		if !ok {
+			t.Fatal("expected a next attempt")
+		}
+		if d := next.Sub(now); d < 80*time.Second || d > 100*time.Second {
+			t.Fatalf("delay %s is outside [80s, 100s]", d)
+		}
+	}
+}
+
+func TestScheduledJobRetry(t *testing.T) {
+	if got := (ScheduledJob{}).Retry(); got != DefaultRetryPolicy {
+		t.Errorf("Retry() = %+v, want the default policy", got)
+	}
+	if got := (ScheduledJob{Retries: 3}).Retry().MaxAttempts; got != 3 {
+		t.Errorf("MaxAttempts = %d, want the retries of the job", got)
+	}
+	custom := RetryPolicy{MaxAttempts: 10, InitialDelay: time.Second, Multiplier: 1, MaxDelay: time.Second}
+	if got := (ScheduledJob{Retries: 3, RetryPolicy: custom}).Retry(); got != custom {
+		t.Errorf("Retry() = %+v, want the policy of the job", got)
+	}
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ const (
 	Success
 	Fail
 	Deleted
+	Paused
 )
 
 func (s ScheduledJobStatus) String() string {
@@ -31,10 +33,21 @@ func (s ScheduledJobStatus) String() string {
 		return "fail"
 	case Deleted:
 		return "deleted"
+	case Paused:
+		return "paused"
 	}
 	return "unknown"
 }
 
+func ParseScheduledJobStatus(s string) (ScheduledJobStatus, error) {
+	for st := Scheduled; st <= Paused; st++ {
+		if st.String() == s {
+			return st, nil
+		}
+	}
+	return Undefined, fmt.Errorf("unknown status %q", s)
+}
+
 type ScheduledJob struct {
 	ID          int64
 	UID         uuid.UUID
@@ -47,8 +60,167 @@ type ScheduledJob struct {
 	Signature   string
 	RunAt       time.Time
 	Retries     int
+	Method      string
+	Headers     map[string]string
+	Query       map[string]string
+	Schedule    string
+	Timezone    string
+	DSTPolicy   DSTPolicy
+	Interval    time.Duration
+	StartAt     time.Time
+	EndAt       time.Time
+	MaxRuns     int
+	Runs        int
+	Tags        []string
+	DedupKey    string
+	DedupPolicy DedupPolicy
 	Status      ScheduledJobStatus
 	Partition   int
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	// SuccessCriteria decides which responses are successful or retried
+	SuccessCriteria SuccessCriteria
+	// RetryPolicy replaces the default policy when its MaxAttempts is set.
+	// See Retry.
+	RetryPolicy RetryPolicy
+	// Attempts counts the failed attempts of the current run and
+	// RunScheduledAt is the run_at of the run before it was retried
+	Attempts       int
+	RunScheduledAt time.Time
+}
+
+// IsRecurring reports whether the job runs on a schedule instead of once
+func (j ScheduledJob) IsRecurring() bool {
+	return len(j.Schedule) > 0 || j.Interval > 0
+}
+
+// ScheduledJobFilter restricts the jobs of a user that a query matches.
+// Zero values do not restrict anything.
+type ScheduledJobFilter struct {
+	UserID      int64
+	Statuses    []ScheduledJobStatus
+	NamePrefix  string
+	UrlHost     string
+	Tags        []string
+	RunAtFrom   time.Time
+	RunAtTo     time.Time
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+// ScheduledJobCursor is the position of the last job of a page
+type ScheduledJobCursor struct {
+	RunAt time.Time
+	ID    int64
+}
+
+// DSTPolicy defines how a recurring schedule behaves around daylight saving
+// time transitions of the job's time zone.
+type DSTPolicy int
+
+const (
+	// DSTRunOnce runs wall clock times that fall in a gap at the same offset
+	// right after the gap and runs repeated wall clock times only once.
+	DSTRunOnce DSTPolicy = iota
+	// DSTSkip skips wall clock times that fall in a gap and runs repeated
+	// wall clock times only once.
+	DSTSkip
+	// DSTRunTwice runs wall clock times that fall in a gap like DSTRunOnce
+	// and runs repeated wall clock times at both instants.
+	DSTRunTwice
+)
+
+func (p DSTPolicy) String() string {
+	switch p {
+	case DSTRunOnce:
+		return "once"
+	case DSTSkip:
+		return "skip"
+	case DSTRunTwice:
+		return "twice"
+	}
+	return "unknown"
+}
+
+func ParseDSTPolicy(s string) (DSTPolicy, error) {
+	switch s {
+	case "", "once":
+		return DSTRunOnce, nil
+	case "skip":
+		return DSTSkip, nil
+	case "twice":
+		return DSTRunTwice, nil
+	}
+	return DSTRunOnce, fmt.Errorf("unknown dst policy %q", s)
+}
+
+// DedupPolicy defines what happens when a job is scheduled with the dedup key
+// of a job that has not run yet.
+type DedupPolicy int
+
+const (
+	// DedupReplace cancels the existing job and schedules the new one
+	DedupReplace DedupPolicy = iota
+	// DedupReject keeps the existing job and rejects the new one
+	DedupReject
+)
+
+func (p DedupPolicy) String() string {
+	switch p {
+	case DedupReplace:
+		return "replace"
+	case DedupReject:
+		return "reject"
+	}
+	return "unknown"
+}
+
+func ParseDedupPolicy(s string) (DedupPolicy, error) {
+	switch s {
+	case "", "replace":
+		return DedupReplace, nil
+	case "reject":
+		return DedupReject, nil
+	}
+	return DedupReplace, fmt.Errorf("unknown dedup policy %q", s)
+}
+
+// MissedRunPolicy defines what happens on resume to a paused job whose runAt
+// passed while it was paused.
+type MissedRunPolicy int
+
+const (
+	// MissedRunFire runs the job immediately
+	MissedRunFire MissedRunPolicy = iota
+	// MissedRunSkip skips the missed run. Recurring jobs move to their next
+	// occurrence and the rest are deleted.
+	MissedRunSkip
+	// MissedRunFail records the missed run as a failed execution. Recurring
+	// jobs move to their next occurrence.
+	MissedRunFail
+)
+
+func (p MissedRunPolicy) String() string {
+	switch p {
+	case MissedRunFire:
+		return "fire"
+	case MissedRunSkip:
+		return "skip"
+	case MissedRunFail:
+		return "fail"
+	}
+	return "unknown"
+}
+
+func ParseMissedRunPolicy(s string) (MissedRunPolicy, error) {
+	switch s {
+	case "", "fire":
+		return MissedRunFire, nil
+	case "skip":
+		return MissedRunSkip, nil
+	case "fail":
+		return MissedRunFail, nil
+	}
+	return MissedRunFire, fmt.Errorf("unknown missed run policy %q", s)
 }
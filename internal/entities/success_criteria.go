@@ -0,0 +1,232 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StatusRange is an inclusive range of HTTP status codes
+type StatusRange struct {
+	From int
+	To   int
+}
+
+// ParseStatusRange parses a status code like 404, a class like 5xx or a
+// range like 500-504
+func ParseStatusRange(s string) (StatusRange, error) {
+	var ans StatusRange
+	invalid := fmt.Errorf("invalid status code range %q", s)
+	s = strings.ToLower(s)
+	switch {
+	case len(s) == 3 && strings.HasSuffix(s, "xx"):
+		class, err := strconv.Atoi(s[:1])
+		if err != nil {
+			return ans, invalid
+		}
+		ans = StatusRange{From: class * 100, To: class*100 + 99}
+	case strings.Contains(s, "-"):
+		from, to, _ := strings.Cut(s, "-")
+		var err1, err2 error
+		ans.From, err1 = strconv.Atoi(from)
+		ans.To, err2 = strconv.Atoi(to)
+		if err1 != nil || err2 != nil {
+			return ans, invalid
+		}
+	default:
+		code, err := strconv.Atoi(s)
+		if err != nil {
+			return ans, invalid
+		}
+		ans = StatusRange{From: code, To: code}
+	}
+	if ans.From < 100 || ans.To > 599 || ans.From > ans.To {
+		return ans, invalid
+	}
+	return ans, nil
+}
+
+func (r StatusRange) String() string {
+	switch {
+	case r.From == r.To:
+		return strconv.Itoa(r.From)
+	case r.From%100 == 0 && r.To == r.From+99:
+		return strconv.Itoa(r.From/100) + "xx"
+	}
+	return strconv.Itoa(r.From) + "-" + strconv.Itoa(r.To)
+}
+
+func (r StatusRange) Contains(code int) bool {
+	return code >= r.From && code <= r.To
+}
+
+// BodyAssertion checks the body of a response. With Equals the JSON value
+// that JSONPath selects must equal the JSON encoded Equals. With Contains
+// the body must contain it.
+type BodyAssertion struct {
+	JSONPath string
+	Equals   string
+	Contains string
+}
+
+func (a BodyAssertion) Check(body []byte) error {
+	if len(a.Contains) > 0 && !bytes.Contains(body, []byte(a.Contains)) {
+		return fmt.Errorf("body does not contain %q", a.Contains)
+	}
+	if len(a.JSONPath) == 0 {
+		return nil
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return errors.New("body is not valid JSON")
+	}
+	got, err := SelectJSONPath(doc, a.JSONPath)
+	if err != nil {
+		return err
+	}
+	var want any
+	if err := json.Unmarshal([]byte(a.Equals), &want); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(got, want) {
+		b, _ := json.Marshal(got)
+		return fmt.Errorf("%s is %s, expected %s", a.JSONPath, b, a.Equals)
+	}
+	return nil
+}
+
+var (
+	// DefaultSuccessCodes are used when a job sets no success codes
+	DefaultSuccessCodes = []StatusRange{{200, 299}}
+	// DefaultRetryableCodes are used when a job sets no retryable codes
+	DefaultRetryableCodes = []StatusRange{{408, 408}, {429, 429}, {500, 599}}
+)
+
+// SuccessCriteria decides the outcome of a delivery from its response.
+// A response succeeds when its status code is a success code and the body
+// passes all the assertions. Responses with a retryable status code are
+// retried and any other response fails the delivery without retries.
+type SuccessCriteria struct {
+	SuccessCodes   []StatusRange
+	RetryableCodes []StatusRange
+	Assertions     []BodyAssertion
+}
+
+func (c SuccessCriteria) IsSuccess(code int) bool {
+	codes := c.SuccessCodes
+	if len(codes) == 0 {
+		codes = DefaultSuccessCodes
+	}
+	return inRanges(codes, code)
+}
+
+// IsRetryable reports whether a response with the status code is retried.
+// Success codes are never retried.
+func (c SuccessCriteria) IsRetryable(code int) bool {
+	if c.IsSuccess(code) {
+		return false
+	}
+	codes := c.RetryableCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryableCodes
+	}
+	return inRanges(codes, code)
+}
+
+// CheckBody returns the error of the first assertion that fails
+func (c SuccessCriteria) CheckBody(body []byte) error {
+	for i := range c.Assertions {
+		if err := c.Assertions[i].Check(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inRanges(ranges []StatusRange, code int) bool {
+	for _, r := range ranges {
+		if r.Contains(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectJSONPath returns the value of doc at path. The path starts with $
+// and is followed by .key, ["key"] and [index] selectors, like
+// $.data.items[0]["id"].
+func SelectJSONPath(doc any, path string) (any, error) {
+	selectors, err := ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, sel := range selectors {
+		switch v := current.(type) {
+		case map[string]any:
+			key, ok := sel.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: not found", path)
+			}
+			if current, ok = v[key]; !ok {
+				return nil, fmt.Errorf("%s: not found", path)
+			}
+		case []any:
+			i, ok := sel.(int)
+			if !ok || i >= len(v) {
+				return nil, fmt.Errorf("%s: not found", path)
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("%s: not found", path)
+		}
+	}
+	return current, nil
+}
+
+// ParseJSONPath returns the selectors of the path, a string for every key
+// and an int for every index
+func ParseJSONPath(path string) ([]any, error) {
+	invalid := fmt.Errorf("invalid JSON path %q", path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, invalid
+	}
+	var ans []any
+	rest := path[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if len(key) == 0 {
+				return nil, invalid
+			}
+			ans = append(ans, key)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, invalid
+			}
+			inner := rest[1:end]
+			if key, err := strconv.Unquote(inner); err == nil && strings.HasPrefix(inner, `"`) {
+				ans = append(ans, key)
+			} else if i, err := strconv.Atoi(inner); err == nil && i >= 0 {
+				ans = append(ans, i)
+			} else {
+				return nil, invalid
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, invalid
+		}
+	}
+	return ans, nil
+}
@@ -0,0 +1,147 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStatusRange(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    StatusRange
+		wantErr bool
+	}{
+		{"404", StatusRange{From: 404, To: 404}, false},
+		{"5xx", StatusRange{From: 500, To: 599}, false},
+		{"2XX", StatusRange{From: 200, To: 299}, false},
+		{"500-504", StatusRange{From: 500, To: 504}, false},
+		{"100-599", StatusRange{From: 100, To: 599}, false},
+		{"504-500", StatusRange{}, true},
+		{"99", StatusRange{}, true},
+		{"600", StatusRange{}, true},
+		{"6xx", StatusRange{}, true},
+		{"axx", StatusRange{}, true},
+		{"50x", StatusRange{}, true},
+		{"500-", StatusRange{}, true},
+		{"", StatusRange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseStatusRange(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStatusRange(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseStatusRange(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestStatusRangeString(t *testing.T) {
+	for _, s := range []string{"404", "5xx", "500-504", "200-399"} {
+		r, err := ParseStatusRange(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.String(); got != s {
+			t.Errorf("String() = %q, want %q", got, s)
+		}
+	}
+}
+
+func TestSuccessCriteria(t *testing.T) {
+	custom := SuccessCriteria{
+		SuccessCodes:   []StatusRange{{From: 200, To: 200}, {From: 404, To: 404}},
+		RetryableCodes: []StatusRange{{From: 503, To: 503}},
+	}
+	tests := []struct {
+		name          string
+		criteria      SuccessCriteria
+		code          int
+		wantSuccess   bool
+		wantRetryable bool
+	}{
+		{"default 2xx", SuccessCriteria{}, 204, true, false},
+		{"default 408", SuccessCriteria{}, 408, false, true},
+		{"default 429", SuccessCriteria{}, 429, false, true},
+		{"default 5xx", SuccessCriteria{}, 502, false, true},
+		{"default 4xx", SuccessCriteria{}, 409, false, false},
+		{"default 3xx", SuccessCriteria{}, 301, false, false},
+		{"custom success", custom, 404, true, false},
+		{"custom not success", custom, 201, false, false},
+		{"custom retryable", custom, 503, false, true},
+		{"custom not retryable", custom, 500, false, false},
+		{"success wins over retryable", SuccessCriteria{
+			SuccessCodes:   []StatusRange{{From: 500, To: 500}},
+			RetryableCodes: []StatusRange{{From: 500, To: 599}},
+		}, 500, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.criteria.IsSuccess(tt.code); got != tt.wantSuccess {
+			t.Errorf("%s: IsSuccess(%d) = %v, want %v", tt.name, tt.code, got, tt.wantSuccess)
+		}
+		if got := tt.criteria.IsRetryable(tt.code); got != tt.wantRetryable {
+			t.Errorf("%s: IsRetryable(%d) = %v, want %v", tt.name, tt.code, got, tt.wantRetryable)
+		}
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []any
+		wantErr bool
+	}{
+		{"$", nil, false},
+		{"$.status", []any{"status"}, false},
+		{"$.data.items[0].id", []any{"data", "items", 0, "id"}, false},
+		{`$["a.b"][2]`, []any{"a.b", 2}, false},
+		{"status", nil, true},
+		{"$.", nil, true},
+		{"$..a", nil, true},
+		{"$[-1]", nil, true},
+		{"$[abc]", nil, true},
+		{"$[0", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseJSONPath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseJSONPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseJSONPath(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBodyAssertionCheck(t *testing.T) {
+	body := []byte(`{"status":"ok","data":{"items":[{"id":7,"tags":["a"]}],"a.b":true},"n":null}`)
+	tests := []struct {
+		name      string
+		assertion BodyAssertion
+		wantErr   bool
+	}{
+		{"string equals", BodyAssertion{JSONPath: "$.status", Equals: `"ok"`}, false},
+		{"string differs", BodyAssertion{JSONPath: "$.status", Equals: `"fail"`}, true},
+		{"number in array", BodyAssertion{JSONPath: "$.data.items[0].id", Equals: `7`}, false},
+		{"number type matters", BodyAssertion{JSONPath: "$.data.items[0].id", Equals: `"7"`}, true},
+		{"object equals", BodyAssertion{JSONPath: "$.data.items[0]", Equals: `{"tags":["a"],"id":7}`}, false},
+		{"quoted key", BodyAssertion{JSONPath: `$.data["a.b"]`, Equals: `true`}, false},
+		{"null", BodyAssertion{JSONPath: "$.n", Equals: `null`}, false},
+		{"missing key", BodyAssertion{JSONPath: "$.missing", Equals: `1`}, true},
+		{"index out of range", BodyAssertion{JSONPath: "$.data.items[1]", Equals: `1`}, true},
+		{"index on object", BodyAssertion{JSONPath: "$.data[0]", Equals: `1`}, true},
+		{"contains", BodyAssertion{Contains: `"status":"ok"`}, false},
+		{"does not contain", BodyAssertion{Contains: "error"}, true},
+	}
+	for _, tt := range tests {
+		err := tt.assertion.Check(body)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Check error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if err := (BodyAssertion{JSONPath: "$.a", Equals: "1"}).Check([]byte("not json")); err == nil {
+		t.Error("expected an error for a body that is not JSON")
+	}
+}
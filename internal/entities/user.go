@@ -7,4 +7,21 @@ type User struct {
 	Username  string
 	ApiKey    string
 	CreatedAt time.Time
+
+	// SigningSecret is the HMAC key used to sign the requests of the user's jobs
+	SigningSecret string
+	// PreviousSigningSecret keeps signing requests after a rotation until
+	// PreviousSigningSecretExpiresAt so receivers can switch without downtime
+	PreviousSigningSecret          string
+	PreviousSigningSecretExpiresAt time.Time
+}
+
+// SigningSecrets returns the secrets that sign requests at the given time,
+// the current one first.
+func (u User) SigningSecrets(now time.Time) []string {
+	ans := []string{u.SigningSecret}
+	if len(u.PreviousSigningSecret) > 0 && now.Before(u.PreviousSigningSecretExpiresAt) {
+		ans = append(ans, u.PreviousSigningSecret)
+	}
+	return ans
 }
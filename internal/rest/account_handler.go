@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+type AccountHandler struct {
+	log zerolog.Logger
+	srv AuthService
+}
+
+type SigningSecretResponse struct {
+	SigningSecret string `json:"signingSecret"`
+	// PreviousExpiresAt is set while the previous secret still signs requests
+	PreviousExpiresAt *time.Time `json:"previousExpiresAt,omitempty"`
+}
+
+func toSigningSecretResponse(u entities.User) SigningSecretResponse {
+	ans := SigningSecretResponse{
+		SigningSecret: u.SigningSecret,
+	}
+	if len(u.SigningSecrets(time.Now().UTC())) > 1 {
+		ans.PreviousExpiresAt = &u.PreviousSigningSecretExpiresAt
+	}
+	return ans
+}
+
+// GetSigningSecret returns the secret that signs the requests of the
+// current user's jobs
+func (h *AccountHandler) GetSigningSecret(w http.ResponseWriter, r bunrouter.Request) error {
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, toSigningSecretResponse(currentUser))
+}
+
+// RotateSigningSecret generates a new signing secret. Requests are signed
+// with both the new and the previous secret for a grace period.
+func (h *AccountHandler) RotateSigningSecret(w http.ResponseWriter, r bunrouter.Request) error {
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	u, err := h.srv.RotateSigningSecret(r.Context(), currentUser)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, toSigningSecretResponse(u))
+}
@@ -0,0 +1,172 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+// maxRedriveDelay is the longest delay of a redrive
+const maxRedriveDelay = 7 * 24 * time.Hour
+
+// DeadLetterResponse is a failed job. Its executions are the attempts of
+// the last run and lastError is the latest of them.
+type DeadLetterResponse struct {
+	ScheduledJobGetResponse
+	LastError *ExecutionResponse `json:"lastError,omitempty"`
+}
+
+func ToDeadLetterResponse(d entities.DeadLetter) DeadLetterResponse {
+	ans := DeadLetterResponse{
+		ScheduledJobGetResponse: ToScheduledJobGetResponse(d.Job, d.Executions),
+	}
+	if len(d.Executions) > 0 {
+		last := ToExecutionResponse(d.Executions[0])
+		ans.LastError = &last
+	}
+	return ans
+}
+
+type DeadLetterListResponse struct {
+	Items      []DeadLetterResponse `json:"items"`
+	NextCursor string               `json:"nextCursor,omitempty"`
+}
+
+// RedrivePayload is the optional body of the redrive endpoints. url
+// replaces the url of the jobs and delay, like 10m, postpones the first
+// attempt of the new run.
+type RedrivePayload struct {
+	Url   string `json:"url"`
+	Delay string `json:"delay"`
+}
+
+func (p RedrivePayload) ToRedrive() (entities.Redrive, error) {
+	ans := entities.Redrive{Url: p.Url}
+	if len(p.Url) > 0 {
+		if _, err := url.ParseRequestURI(p.Url); err != nil {
+			return ans, ValidationError{err.Error()}
+		}
+	}
+	if len(p.Delay) > 0 {
+		d, err := time.ParseDuration(p.Delay)
+		if err != nil {
+			return ans, ValidationError{"invalid delay: " + err.Error()}
+		}
+		if d < 0 || d > maxRedriveDelay {
+			return ans, ValidationError{"delay must be between 0s and " + maxRedriveDelay.String()}
+		}
+		ans.Delay = d
+	}
+	return ans, nil
+}
+
+// RedriveMatchingPayload selects the failed jobs to redrive with the fields
+// of ScheduledJobsFilterPayload, except status, and sets the redrive
+type RedriveMatchingPayload struct {
+	ScheduledJobsFilterPayload
+	RedrivePayload
+}
+
+// ListDeadLetters returns the failed jobs of the current user with the
+// attempts of their last run. It takes the query parameters of List except
+// status.
+func (h *ScheduledJobsHandler) ListDeadLetters(w http.ResponseWriter, r bunrouter.Request) error {
+	q := r.URL.Query()
+	fp, err := filterFromQuery(q)
+	if err != nil {
+		return err
+	}
+	if len(fp.Status) > 0 {
+		return ValidationError{"status cannot be set, dead letters are failed jobs"}
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	filter, err := fp.ToFilter(currentUser.ID)
+	if err != nil {
+		return err
+	}
+	page, err := pageFromQuery(q)
+	if err != nil {
+		return err
+	}
+	// fetch one more to know if there is a next page
+	items, err := h.srv.ListDeadLetters(r.Context(), filter, page.after, page.limit+1, page.desc)
+	if err != nil {
+		return err
+	}
+	ans := DeadLetterListResponse{
+		Items: make([]DeadLetterResponse, 0, len(items)),
+	}
+	if len(items) > page.limit {
+		items = items[:page.limit]
+		ans.NextCursor = page.next(items[len(items)-1].Job)
+	}
+	for i := range items {
+		ans.Items = append(ans.Items, ToDeadLetterResponse(items[i]))
+	}
+	return JSON(w, http.StatusOK, ans)
+}
+
+// Redrive schedules a new run of a failed job
+func (h *ScheduledJobsHandler) Redrive(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	var p RedrivePayload
+	if err := Bind(r, &p); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	redrive, err := p.ToRedrive()
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, err := h.srv.Redrive(r.Context(), currentUser, id.String(), redrive)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, nil))
+}
+
+// RedriveMatching redrives in the background all the failed jobs of the
+// current user that match the filter in the body. An empty filter matches
+// every failed job. The progress is reported at /operations/:uuid.
+func (h *ScheduledJobsHandler) RedriveMatching(w http.ResponseWriter, r bunrouter.Request) error {
+	var p RedriveMatchingPayload
+	if err := Bind(r, &p); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if len(p.Status) > 0 {
+		return ValidationError{"status cannot be set, only failed jobs are redriven"}
+	}
+	redrive, err := p.ToRedrive()
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	filter, err := p.ToFilter(currentUser.ID)
+	if err != nil {
+		return err
+	}
+	op, err := h.srv.RedriveMatching(r.Context(), filter, redrive)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusAccepted, ToBulkOperationResponse(op))
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/entities"
 )
 
 var ErrNotFound = errors.New("resource not found")
@@ -26,6 +28,14 @@ func (e ValidationError) Error() string {
 	return e.Message
 }
 
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
 type HTTPError struct {
 	StatusCode int `json:"-"`
 
@@ -48,6 +58,64 @@ func NewHTTPError(err error) HTTPError {
 			StatusCode: http.StatusBadRequest,
 			Message:    err.Message,
 		}
+	case ConflictError:
+		return HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    err.Message,
+		}
+	}
+
+	switch {
+	case errors.Is(err, entities.ErrJobNotFound):
+		return HTTPError{
+			StatusCode: http.StatusNotFound,
+			Message:    "resource not found",
+		}
+	case errors.Is(err, entities.ErrOperationNotFound):
+		return HTTPError{
+			StatusCode: http.StatusNotFound,
+			Message:    "resource not found",
+		}
+	case errors.Is(err, entities.ErrJobNotScheduled):
+		return HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    err.Error(),
+		}
+	case errors.Is(err, entities.ErrJobNotPaused):
+		return HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    err.Error(),
+		}
+	case errors.Is(err, entities.ErrJobNotFailed):
+		return HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    err.Error(),
+		}
+	case errors.Is(err, entities.ErrJobNotFinished):
+		return HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    err.Error(),
+		}
+	case errors.Is(err, entities.ErrOperationRunning):
+		return HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    err.Error(),
+		}
+	case errors.Is(err, entities.ErrDuplicateJob):
+		return HTTPError{
+			StatusCode: http.StatusConflict,
+			Message:    err.Error(),
+		}
+	case errors.Is(err, entities.ErrIdempotencyKeyMismatch):
+		return HTTPError{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    err.Error(),
+		}
+	case errors.Is(err, entities.ErrRateLimited):
+		return HTTPError{
+			StatusCode: http.StatusTooManyRequests,
+			Message:    err.Error(),
+		}
 	}
 
 	switch err {
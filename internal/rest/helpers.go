@@ -38,6 +38,14 @@ func JSON(w http.ResponseWriter, statusCode int, value interface{}) error {
 	return nil
 }
 
+// RawJSON writes an already encoded JSON body
+func RawJSON(w http.ResponseWriter, statusCode int, body []byte) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, err := w.Write(body)
+	return err
+}
+
 func logHandler(log zerolog.Logger) func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
 	return func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
 		return func(w http.ResponseWriter, req bunrouter.Request) error {
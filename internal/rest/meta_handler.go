@@ -1,24 +1,55 @@
 package rest
 
 import (
+	"crypto/ecdsa"
 	"net/http"
 
 	"github.com/rs/zerolog"
 	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/cryptoutils"
 )
 
 type MetaResponse struct {
+	// PublicKey is the PEM encoded key that signs the requests
+	PublicKey string `json:"publicKey"`
+	// Keys lists every key a request may be signed with. The key of a request
+	// is the one with the kid of its X-HERMESHOOKS-KEY-ID header.
+	Keys []PublicKeyResponse `json:"keys"`
+}
+
+type PublicKeyResponse struct {
+	KID       string `json:"kid"`
+	Alg       string `json:"alg"`
 	PublicKey string `json:"publicKey"`
 }
 
 type MetaHandler struct {
-	log    zerolog.Logger
-	pubKey string
+	log  zerolog.Logger
+	keys []*ecdsa.PublicKey
 }
 
 func (h *MetaHandler) Get(w http.ResponseWriter, r bunrouter.Request) error {
 	ans := MetaResponse{
-		PublicKey: h.pubKey,
+		Keys: make([]PublicKeyResponse, 0, len(h.keys)),
+	}
+	for i := range h.keys {
+		pem, err := cryptoutils.MarshalECDSAPublicKey(h.keys[i])
+		if err != nil {
+			return err
+		}
+		kid, err := cryptoutils.KeyID(h.keys[i])
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			ans.PublicKey = pem
+		}
+		ans.Keys = append(ans.Keys, PublicKeyResponse{
+			KID:       kid,
+			Alg:       "ES256",
+			PublicKey: pem,
+		})
 	}
 	return JSON(w, http.StatusOK, ans)
 }
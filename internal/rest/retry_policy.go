@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"time"
+
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+// RetryPolicyPayload sets how the failed attempts of a run are retried.
+// Durations are like 30s or 10m and omitted fields take their default
+// value. A maxAttempts of 0 removes the policy and then a run makes as
+// many attempts as the retries of the job, at least one.
+type RetryPolicyPayload struct {
+	MaxAttempts  int      `json:"maxAttempts"`
+	InitialDelay string   `json:"initialDelay,omitempty"`
+	Multiplier   float64  `json:"multiplier,omitempty"`
+	MaxDelay     string   `json:"maxDelay,omitempty"`
+	Jitter       *float64 `json:"jitter,omitempty"`
+	MaxAge       string   `json:"maxAge,omitempty"`
+}
+
+func (p *RetryPolicyPayload) Validate() error {
+	if p == nil || p.MaxAttempts == 0 {
+		return nil
+	}
+	if p.MaxAttempts < 0 || p.MaxAttempts > 25 {
+		return ValidationError{"retryPolicy.maxAttempts must be between 0 and 25"}
+	}
+	durations := []struct {
+		name     string
+		value    string
+		min, max time.Duration
+	}{
+		{"initialDelay", p.InitialDelay, time.Second, 24 * time.Hour},
+		{"maxDelay", p.MaxDelay, time.Second, 24 * time.Hour},
+		{"maxAge", p.MaxAge, time.Minute, 7 * 24 * time.Hour},
+	}
+	for _, d := range durations {
+		if len(d.value) == 0 {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return ValidationError{"invalid retryPolicy." + d.name + ": " + err.Error()}
+		}
+		if v < d.min || v > d.max {
+			return ValidationError{"retryPolicy." + d.name + " must be between " + d.min.String() + " and " + d.max.String()}
+		}
+	}
+	if p.Multiplier != 0 && (p.Multiplier < 1 || p.Multiplier > 10) {
+		return ValidationError{"retryPolicy.multiplier must be between 1 and 10"}
+	}
+	if p.Jitter != nil && (*p.Jitter < 0 || *p.Jitter > 1) {
+		return ValidationError{"retryPolicy.jitter must be between 0 and 1"}
+	}
+	policy := ToRetryPolicy(p)
+	if policy.MaxDelay < policy.InitialDelay {
+		return ValidationError{"retryPolicy.maxDelay cannot be less than initialDelay"}
+	}
+	return nil
+}
+
+func ToRetryPolicy(p *RetryPolicyPayload) entities.RetryPolicy {
+	if p == nil || p.MaxAttempts == 0 {
+		return entities.RetryPolicy{}
+	}
+	ans := entities.DefaultRetryPolicy
+	ans.MaxAttempts = p.MaxAttempts
+	if len(p.InitialDelay) > 0 {
+		ans.InitialDelay, _ = time.ParseDuration(p.InitialDelay)
+	}
+	if p.Multiplier != 0 {
+		ans.Multiplier = p.Multiplier
+	}
+	if len(p.MaxDelay) > 0 {
+		ans.MaxDelay, _ = time.ParseDuration(p.MaxDelay)
+	}
+	if p.Jitter != nil {
+		ans.Jitter = *p.Jitter
+	}
+	if len(p.MaxAge) > 0 {
+		ans.MaxAge, _ = time.ParseDuration(p.MaxAge)
+	}
+	return ans
+}
+
+// FromRetryPolicy returns nil for jobs without a retry policy
+func FromRetryPolicy(p entities.RetryPolicy) *RetryPolicyPayload {
+	if p.MaxAttempts == 0 {
+		return nil
+	}
+	jitter := p.Jitter
+	return &RetryPolicyPayload{
+		MaxAttempts:  p.MaxAttempts,
+		InitialDelay: p.InitialDelay.String(),
+		Multiplier:   p.Multiplier,
+		MaxDelay:     p.MaxDelay.String(),
+		Jitter:       &jitter,
+		MaxAge:       p.MaxAge.String(),
+	}
+}
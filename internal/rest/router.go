@@ -12,7 +12,8 @@ import (
 )
 
 type AuthService interface {
-	Signup(ctx context.Context, username string) (string, error)
+	Signup(ctx context.Context, username string) (entities.User, error)
+	RotateSigningSecret(ctx context.Context, u entities.User) (entities.User, error)
 	AuthMiddleware(next bunrouter.HandlerFunc) bunrouter.HandlerFunc
 	InternalApi(next bunrouter.HandlerFunc) bunrouter.HandlerFunc
 }
@@ -20,6 +21,23 @@ type AuthService interface {
 type ScheduledJobService interface {
 	Get(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, []entities.Execution, error)
 	Schedule(ctx context.Context, job entities.ScheduledJob) (entities.ScheduledJob, error)
+	ScheduleBatch(ctx context.Context, jobs []entities.ScheduledJob) ([]entities.ScheduledJob, []error, error)
+	ScheduleIdempotent(ctx context.Context, key entities.IdempotencyKey, build func() (entities.ScheduledJob, error)) (entities.IdempotencyKey, error)
+	List(ctx context.Context, f entities.ScheduledJobFilter, after entities.ScheduledJobCursor, limit int, desc bool) ([]entities.ScheduledJob, error)
+	Cancel(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error)
+	CancelMatching(ctx context.Context, f entities.ScheduledJobFilter) (entities.BulkOperation, error)
+	GetOperation(ctx context.Context, u entities.User, uid string) (entities.BulkOperation, error)
+	Pause(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error)
+	Resume(ctx context.Context, u entities.User, uid string, policy entities.MissedRunPolicy) (entities.ScheduledJob, error)
+	PauseAll(ctx context.Context, u entities.User) (int64, error)
+	ResumeAll(ctx context.Context, u entities.User, policy entities.MissedRunPolicy) (int64, error)
+	Update(ctx context.Context, u entities.User, uid string, apply func(entities.ScheduledJob) (entities.ScheduledJob, error)) (entities.ScheduledJob, error)
+	ListDeadLetters(ctx context.Context, f entities.ScheduledJobFilter, after entities.ScheduledJobCursor, limit int, desc bool) ([]entities.DeadLetter, error)
+	Redrive(ctx context.Context, u entities.User, uid string, r entities.Redrive) (entities.ScheduledJob, error)
+	RedriveMatching(ctx context.Context, f entities.ScheduledJobFilter, r entities.Redrive) (entities.BulkOperation, error)
+	Trigger(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error)
+	Replay(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error)
+	TestDelivery(ctx context.Context, u entities.User, job entities.ScheduledJob) (entities.TestDelivery, error)
 }
 
 type WorkerService interface {
@@ -36,7 +54,9 @@ type RouterConfig struct {
 	ScheduledJobSrv ScheduledJobService
 	WorkerSrv       WorkerService
 	AuthSrv         AuthService
-	PublicKey       *ecdsa.PublicKey
+	// PublicKeys are published on /meta, the key that currently signs
+	// requests first
+	PublicKeys []*ecdsa.PublicKey
 }
 
 func NewRouter(cfg RouterConfig) *bunrouter.Router {
@@ -64,6 +84,16 @@ func NewRouter(cfg RouterConfig) *bunrouter.Router {
 			group.POST("", userHandler.Create)
 		})
 
+		g.WithGroup("/account", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			accountHandler := AccountHandler{
+				log: cfg.Log,
+				srv: cfg.AuthSrv,
+			}
+			group.GET("/signingSecret", accountHandler.GetSigningSecret)
+			group.POST("/signingSecret/rotate", accountHandler.RotateSigningSecret)
+		})
+
 		g.WithGroup("/workers", func(group *bunrouter.Group) {
 			group = group.Use(cfg.AuthSrv.InternalApi)
 			workerHandler := WorkerHandler{
@@ -77,21 +107,70 @@ func NewRouter(cfg RouterConfig) *bunrouter.Router {
 
 		g.WithGroup("/meta", func(group *bunrouter.Group) {
 			metaHandler := MetaHandler{
-				log: cfg.Log,
+				log:  cfg.Log,
+				keys: cfg.PublicKeys,
 			}
 			group.GET("", metaHandler.Get)
 		})
 
+		scheduledJobsHandler := ScheduledJobsHandler{
+			log: cfg.Log,
+			srv: cfg.ScheduledJobSrv,
+		}
 		g.WithGroup("/scheduledJobs", func(group *bunrouter.Group) {
 			group = group.Use(cfg.AuthSrv.AuthMiddleware)
-			scheduledJobsHandler := ScheduledJobsHandler{
-				log: cfg.Log,
-				srv: cfg.ScheduledJobSrv,
-			}
+			group.GET("", scheduledJobsHandler.List)
 			group.GET("/:uuid", scheduledJobsHandler.Get)
+			group.PATCH("/:uuid", scheduledJobsHandler.Update)
+			group.DELETE("/:uuid", scheduledJobsHandler.Delete)
+			group.POST("/:uuid/pause", scheduledJobsHandler.Pause)
+			group.POST("/:uuid/resume", scheduledJobsHandler.Resume)
+			group.POST("/:uuid/trigger", scheduledJobsHandler.Trigger)
+			group.POST("/:uuid/replay", scheduledJobsHandler.Replay)
 			group.POST("", scheduledJobsHandler.Create)
 		})
 
+		g.WithGroup("/scheduledJobs:batch", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.POST("", scheduledJobsHandler.Batch)
+		})
+
+		g.WithGroup("/scheduledJobs:test", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.POST("", scheduledJobsHandler.TestDelivery)
+		})
+
+		g.WithGroup("/scheduledJobs:cancel", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.POST("", scheduledJobsHandler.CancelMatching)
+		})
+
+		g.WithGroup("/scheduledJobs:pause", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.POST("", scheduledJobsHandler.PauseAll)
+		})
+
+		g.WithGroup("/scheduledJobs:resume", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.POST("", scheduledJobsHandler.ResumeAll)
+		})
+
+		g.WithGroup("/deadLetters", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.GET("", scheduledJobsHandler.ListDeadLetters)
+			group.POST("/:uuid/redrive", scheduledJobsHandler.Redrive)
+		})
+
+		g.WithGroup("/deadLetters:redrive", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.POST("", scheduledJobsHandler.RedriveMatching)
+		})
+
+		g.WithGroup("/operations", func(group *bunrouter.Group) {
+			group = group.Use(cfg.AuthSrv.AuthMiddleware)
+			group.GET("/:uuid", scheduledJobsHandler.GetOperation)
+		})
+
 	})
 	return router
 }
@@ -0,0 +1,170 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+// maxBatchSize is the maximum number of jobs created by one batch request
+const maxBatchSize = 1000
+
+type ScheduledJobsBatchPayload struct {
+	Items []ScheduledJobsPayload `json:"items"`
+}
+
+// ScheduledJobsBatchItem holds either the uuid of the created job or the
+// reason the item was rejected.
+type ScheduledJobsBatchItem struct {
+	UUID  string `json:"uuid,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type ScheduledJobsBatchResponse struct {
+	Items []ScheduledJobsBatchItem `json:"items"`
+}
+
+// Batch creates many jobs in one transaction. Every item is validated on
+// its own and the response holds the outcome of each item at the same index.
+func (h *ScheduledJobsHandler) Batch(w http.ResponseWriter, r bunrouter.Request) error {
+	if len(r.Header.Get("Idempotency-Key")) > 0 {
+		return ValidationError{"Idempotency-Key is not supported for batches"}
+	}
+	var p ScheduledJobsBatchPayload
+	if err := Bind(r, &p); err != nil {
+		return err
+	}
+	if len(p.Items) == 0 {
+		return ValidationError{"items cannot be empty"}
+	}
+	if len(p.Items) > maxBatchSize {
+		return ValidationError{"items cannot be more than " + strconv.Itoa(maxBatchSize)}
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	ans := ScheduledJobsBatchResponse{
+		Items: make([]ScheduledJobsBatchItem, len(p.Items)),
+	}
+	jobs := make([]entities.ScheduledJob, 0, len(p.Items))
+	positions := make([]int, 0, len(p.Items))
+	dedupKeys := make(map[string]bool)
+	for i := range p.Items {
+		if err := validateBatchItem(p.Items[i], dedupKeys); err != nil {
+			ans.Items[i].Error = err.Error()
+			continue
+		}
+		job := ToScheduledJob(p.Items[i])
+		job.UserID = currentUser.ID
+		jobs = append(jobs, job)
+		positions = append(positions, i)
+	}
+	if len(jobs) > 0 {
+		created, errs, err := h.srv.ScheduleBatch(r.Context(), jobs)
+		if err != nil {
+			return err
+		}
+		for k, i := range positions {
+			if errs[k] != nil {
+				ans.Items[i].Error = errs[k].Error()
+				continue
+			}
+			ans.Items[i].UUID = created[k].UID.String()
+		}
+	}
+	return JSON(w, http.StatusOK, ans)
+}
+
+func validateBatchItem(p ScheduledJobsPayload, dedupKeys map[string]bool) error {
+	if len(p.IdempotencyKey) > 0 {
+		return ValidationError{"idempotencyKey is not supported for batch items"}
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if len(p.DedupKey) > 0 {
+		if dedupKeys[p.DedupKey] {
+			return ValidationError{"dedupKey is used by another item of the batch"}
+		}
+		dedupKeys[p.DedupKey] = true
+	}
+	return nil
+}
+
+type BulkOperationResponse struct {
+	UID       uuid.UUID `json:"uid"`
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Affected  int64     `json:"affected"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func ToBulkOperationResponse(op entities.BulkOperation) BulkOperationResponse {
+	return BulkOperationResponse{
+		UID:       op.UID,
+		Kind:      op.Kind,
+		Status:    op.Status.String(),
+		Affected:  op.Affected,
+		Error:     op.Error,
+		CreatedAt: op.CreatedAt,
+		UpdatedAt: op.UpdatedAt,
+	}
+}
+
+// CancelMatching cancels in the background all the scheduled and paused jobs
+// of the current user that match the filter in the body. The filter must
+// set at least one field so that a missing body does not cancel every job.
+// The progress is reported at /operations/:uuid.
+// A user can run one bulk operation at a time.
+func (h *ScheduledJobsHandler) CancelMatching(w http.ResponseWriter, r bunrouter.Request) error {
+	var p ScheduledJobsFilterPayload
+	if err := Bind(r, &p); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if len(p.Status) > 0 {
+		return ValidationError{"status cannot be set, only scheduled and paused jobs are cancelled"}
+	}
+	if p.isEmpty() {
+		return ValidationError{"a filter is required, set at least one of namePrefix, urlHost, tags, runAtFrom, runAtTo, createdFrom and createdTo"}
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	filter, err := p.ToFilter(currentUser.ID)
+	if err != nil {
+		return err
+	}
+	op, err := h.srv.CancelMatching(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusAccepted, ToBulkOperationResponse(op))
+}
+
+func (h *ScheduledJobsHandler) GetOperation(w http.ResponseWriter, r bunrouter.Request) error {
+	uid, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	op, err := h.srv.GetOperation(r.Context(), currentUser, uid.String())
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToBulkOperationResponse(op))
+}
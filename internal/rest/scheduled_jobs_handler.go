@@ -1,9 +1,11 @@
 package rest
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -12,7 +14,9 @@ import (
 	"github.com/uptrace/bunrouter"
 
 	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/cryptoutils"
 	"github.com/gosom/hermeshooks/internal/entities"
+	"github.com/gosom/hermeshooks/internal/schedule"
 )
 
 var supportedContentTypes map[string]bool = map[string]bool{
@@ -23,17 +27,60 @@ var supportedContentTypes map[string]bool = map[string]bool{
 
 // TODO validate
 type ScheduledJobsPayload struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Url         string    `json:"url"`
-	Payload     string    `json:"payload"`
-	ContentType string    `json:"contentType"`
-	Signature   string    `json:"signature"`
-	RunAt       time.Time `json:"runAt"`
-	Retries     int       `json:"retries"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Url         string            `json:"url"`
+	Payload     string            `json:"payload"`
+	ContentType string            `json:"contentType"`
+	Signature   string            `json:"signature"`
+	RunAt       time.Time         `json:"runAt"`
+	Retries     int               `json:"retries"`
+	Method      string            `json:"method"`
+	Headers     map[string]string `json:"headers"`
+	Query       map[string]string `json:"query"`
+	Schedule    string            `json:"schedule"`
+	Timezone    string            `json:"timezone"`
+	DSTPolicy   string            `json:"dstPolicy"`
+	Interval    string            `json:"interval"`
+	StartAt     time.Time         `json:"startAt"`
+	EndAt       time.Time         `json:"endAt"`
+	MaxRuns     int               `json:"maxRuns"`
+	Tags        []string          `json:"tags"`
+	DedupKey    string            `json:"dedupKey"`
+	DedupPolicy string            `json:"dedupPolicy"`
+
+	// SuccessCriteria replaces the default success criteria when set
+	SuccessCriteria *SuccessCriteriaPayload `json:"successCriteria,omitempty"`
+	// RetryPolicy is used instead of retries when set
+	RetryPolicy *RetryPolicyPayload `json:"retryPolicy,omitempty"`
+
+	// IdempotencyKey can be used instead of the Idempotency-Key header
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 func (s ScheduledJobsPayload) Validate() error {
+	if err := s.validateFields(); err != nil {
+		return err
+	}
+	if err := s.validateRecurrence(); err != nil {
+		return err
+	}
+	// when runAt is omitted the first run is computed from the schedule
+	if s.RunAt.IsZero() && s.isRecurring() {
+		first := s.firstRun(time.Now().UTC())
+		if first.IsZero() {
+			return ValidationError{"schedule has no runs between startAt and endAt"}
+		}
+		if first.After(time.Now().UTC().Add(24 * time.Hour * 30)) {
+			return ValidationError{"first run must be at most 30 days from now"}
+		}
+		return nil
+	}
+	return s.validateRunAt()
+}
+
+// validateFields validates everything apart from the timing of the job
+func (s ScheduledJobsPayload) validateFields() error {
 	if len(s.Name) == 0 {
 		return ValidationError{"name is mandatory"}
 	}
@@ -63,6 +110,39 @@ func (s ScheduledJobsPayload) Validate() error {
 	if len(s.Signature) > 64 {
 		return ValidationError{"signature can be at most 64 characters"}
 	}
+	if s.Retries > 3 {
+		return ValidationError{
+			"retries can be at most 3",
+		}
+	}
+	if !supportedMethods[strings.ToUpper(s.Method)] {
+		return ValidationError{"method must be one of: GET,POST,PUT,PATCH,DELETE"}
+	}
+	if err := validateHeaders(s.Headers); err != nil {
+		return err
+	}
+	if err := validateQuery(s.Query); err != nil {
+		return err
+	}
+	if err := s.SuccessCriteria.Validate(); err != nil {
+		return err
+	}
+	if err := s.RetryPolicy.Validate(); err != nil {
+		return err
+	}
+	if len(s.DedupKey) > 128 {
+		return ValidationError{"dedupKey can be at most 128 characters"}
+	}
+	if _, err := entities.ParseDedupPolicy(s.DedupPolicy); err != nil {
+		return ValidationError{"dedupPolicy must be one of: replace,reject"}
+	}
+	if len(s.DedupKey) == 0 && len(s.DedupPolicy) > 0 {
+		return ValidationError{"dedupPolicy requires a dedupKey"}
+	}
+	return validateTags(s.Tags)
+}
+
+func (s ScheduledJobsPayload) validateRunAt() error {
 	now := time.Now().UTC().Add(3 * time.Minute)
 	if s.RunAt.UTC().Before(now) {
 		msg := fmt.Sprintf("RunAt must be at least 3 minutes from now")
@@ -73,14 +153,171 @@ func (s ScheduledJobsPayload) Validate() error {
 		msg := fmt.Sprintf("RunAt must be at most 30 days from now")
 		return ValidationError{msg}
 	}
-	if s.Retries > 3 {
-		return ValidationError{
-			"retries can be at most 3",
+	return nil
+}
+
+var supportedMethods = map[string]bool{
+	"":                true,
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// deniedHeaders are the hop-by-hop headers and the headers that are set
+// by the executor itself
+var deniedHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Proxy-Connection":    true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+	"Host":                true,
+	"Content-Length":      true,
+	"Content-Type":        true,
+}
+
+var headerNameRe = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]{1,64}$")
+
+func validateHeaders(headers map[string]string) error {
+	if len(headers) > 20 {
+		return ValidationError{"a job can have at most 20 headers"}
+	}
+	for k, v := range headers {
+		if !headerNameRe.MatchString(k) {
+			return ValidationError{fmt.Sprintf("invalid header name %q", k)}
+		}
+		name := http.CanonicalHeaderKey(k)
+		if deniedHeaders[name] || strings.HasPrefix(name, "X-Hermeshooks-") {
+			return ValidationError{fmt.Sprintf("header %s cannot be set", name)}
+		}
+		if len(v) > 1024 || strings.ContainsAny(v, "\r\n\x00") {
+			return ValidationError{fmt.Sprintf("invalid value for header %s", name)}
+		}
+	}
+	return nil
+}
+
+func validateQuery(query map[string]string) error {
+	if len(query) > 20 {
+		return ValidationError{"a job can have at most 20 query parameters"}
+	}
+	for k, v := range query {
+		if len(k) == 0 || len(k) > 64 {
+			return ValidationError{"query parameter names must be 1 to 64 characters"}
+		}
+		if len(v) > 1024 {
+			return ValidationError{fmt.Sprintf("query parameter %s can be at most 1024 characters", k)}
+		}
+	}
+	return nil
+}
+
+var tagRe = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,32}$`)
+
+func validateTags(tags []string) error {
+	if len(tags) > 10 {
+		return ValidationError{"a job can have at most 10 tags"}
+	}
+	for _, tag := range tags {
+		if !tagRe.MatchString(tag) {
+			return ValidationError{
+				"tags must be 1 to 32 characters of letters, digits and _.:-",
+			}
 		}
 	}
 	return nil
 }
 
+func (s ScheduledJobsPayload) isRecurring() bool {
+	return len(s.Schedule) > 0 || len(s.Interval) > 0
+}
+
+// firstRun returns the first run of a recurring job without a runAt that is
+// created at now. It is zero when the schedule has no runs.
+func (s ScheduledJobsPayload) firstRun(now time.Time) time.Time {
+	job := entities.ScheduledJob{
+		Schedule: s.Schedule,
+		Timezone: s.Timezone,
+		StartAt:  s.StartAt.UTC(),
+		EndAt:    s.EndAt.UTC(),
+		MaxRuns:  s.MaxRuns,
+	}
+	job.DSTPolicy, _ = entities.ParseDSTPolicy(s.DSTPolicy)
+	if len(s.Interval) > 0 {
+		job.Interval, _ = time.ParseDuration(s.Interval)
+	}
+	first, _ := schedule.Next(job, now.Add(3*time.Minute))
+	return first
+}
+
+func (s ScheduledJobsPayload) validateRecurrence() error {
+	if len(s.Schedule) > 0 && len(s.Interval) > 0 {
+		return ValidationError{"use either schedule or interval, not both"}
+	}
+	if len(s.Schedule) == 0 && (len(s.Timezone) > 0 || len(s.DSTPolicy) > 0) {
+		return ValidationError{"timezone and dstPolicy require a schedule"}
+	}
+	if !s.isRecurring() && (!s.StartAt.IsZero() || !s.EndAt.IsZero() || s.MaxRuns != 0) {
+		return ValidationError{"startAt, endAt and maxRuns require a schedule or an interval"}
+	}
+	if len(s.Schedule) > 128 {
+		return ValidationError{"schedule can be at most 128 characters"}
+	}
+	if len(s.Schedule) > 0 {
+		if _, err := schedule.Parse(s.Schedule); err != nil {
+			return ValidationError{"invalid schedule: " + err.Error()}
+		}
+	}
+	if len(s.Timezone) > 64 {
+		return ValidationError{"timezone can be at most 64 characters"}
+	}
+	if _, err := schedule.LoadLocation(s.Timezone); err != nil {
+		return ValidationError{"invalid timezone: " + err.Error()}
+	}
+	if _, err := entities.ParseDSTPolicy(s.DSTPolicy); err != nil {
+		return ValidationError{"dstPolicy must be one of: once,skip,twice"}
+	}
+	if len(s.Interval) > 0 {
+		d, err := time.ParseDuration(s.Interval)
+		if err != nil {
+			return ValidationError{"invalid interval: " + err.Error()}
+		}
+		if d < schedule.MinFrequency {
+			return ValidationError{"interval must be at least 1m"}
+		}
+		if d%time.Second != 0 {
+			return ValidationError{"interval must be a whole number of seconds"}
+		}
+		if s.RunAt.IsZero() && s.StartAt.IsZero() {
+			return ValidationError{"interval requires runAt or startAt"}
+		}
+	}
+	if !s.RunAt.IsZero() && !s.StartAt.IsZero() && s.RunAt.Before(s.StartAt) {
+		return ValidationError{"runAt cannot be before startAt"}
+	}
+	if !s.EndAt.IsZero() {
+		if !s.EndAt.After(time.Now().UTC()) {
+			return ValidationError{"endAt must be in the future"}
+		}
+		if !s.StartAt.IsZero() && !s.EndAt.After(s.StartAt) {
+			return ValidationError{"endAt must be after startAt"}
+		}
+		if s.RunAt.After(s.EndAt) {
+			return ValidationError{"runAt must be before endAt"}
+		}
+	}
+	if s.MaxRuns < 0 {
+		return ValidationError{"maxRuns cannot be negative"}
+	}
+	return nil
+}
+
 func ToScheduledJob(p ScheduledJobsPayload) entities.ScheduledJob {
 	ans := entities.ScheduledJob{
 		UID:         uuid.New(),
@@ -92,9 +329,178 @@ func ToScheduledJob(p ScheduledJobsPayload) entities.ScheduledJob {
 		Signature:   p.Signature,
 		RunAt:       p.RunAt,
 		Retries:     p.Retries,
+		Method:      normalizeMethod(p.Method),
+		Headers:     canonicalHeaders(p.Headers),
+		Query:       p.Query,
+		Schedule:    p.Schedule,
+		Timezone:    p.Timezone,
+		StartAt:     p.StartAt.UTC(),
+		EndAt:       p.EndAt.UTC(),
+		MaxRuns:     p.MaxRuns,
+		Tags:        p.Tags,
+		DedupKey:    p.DedupKey,
 		Status:      entities.Scheduled,
 		CreatedAt:   time.Now().UTC(),
 	}
+	ans.DSTPolicy, _ = entities.ParseDSTPolicy(p.DSTPolicy)
+	ans.DedupPolicy, _ = entities.ParseDedupPolicy(p.DedupPolicy)
+	ans.SuccessCriteria = ToSuccessCriteria(p.SuccessCriteria)
+	ans.RetryPolicy = ToRetryPolicy(p.RetryPolicy)
+	if len(p.Interval) > 0 {
+		ans.Interval, _ = time.ParseDuration(p.Interval)
+		// interval runs are anchored at startAt
+		if ans.StartAt.IsZero() {
+			ans.StartAt = ans.RunAt
+		}
+	}
+	if ans.RunAt.IsZero() {
+		ans.RunAt = p.firstRun(ans.CreatedAt)
+	}
+	return ans
+}
+
+func normalizeMethod(method string) string {
+	if len(method) == 0 {
+		return http.MethodPost
+	}
+	return strings.ToUpper(method)
+}
+
+func canonicalHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	ans := make(map[string]string, len(headers))
+	for k, v := range headers {
+		ans[http.CanonicalHeaderKey(k)] = v
+	}
+	return ans
+}
+
+// ScheduledJobsPatchPayload contains the fields of a scheduled job that can
+// be edited. Omitted fields keep their current value.
+type ScheduledJobsPatchPayload struct {
+	Name        *string            `json:"name"`
+	Description *string            `json:"description"`
+	Url         *string            `json:"url"`
+	Payload     *string            `json:"payload"`
+	ContentType *string            `json:"contentType"`
+	RunAt       *time.Time         `json:"runAt"`
+	Retries     *int               `json:"retries"`
+	Method      *string            `json:"method"`
+	Headers     *map[string]string `json:"headers"`
+	Query       *map[string]string `json:"query"`
+
+	// SuccessCriteria replaces the criteria, {} restores the defaults
+	SuccessCriteria *SuccessCriteriaPayload `json:"successCriteria"`
+	// RetryPolicy replaces the policy, {} removes it
+	RetryPolicy *RetryPolicyPayload `json:"retryPolicy"`
+}
+
+// Apply validates the patch against the current job and returns the
+// updated job. The ScheduledJobsPayload rules apply to the result.
+func (p ScheduledJobsPatchPayload) Apply(job entities.ScheduledJob) (entities.ScheduledJob, error) {
+	merged := FromScheduledJob(job)
+	if p.Name != nil {
+		merged.Name = *p.Name
+	}
+	if p.Description != nil {
+		merged.Description = *p.Description
+	}
+	if p.Url != nil {
+		merged.Url = *p.Url
+	}
+	if p.Payload != nil {
+		merged.Payload = *p.Payload
+	}
+	if p.ContentType != nil {
+		merged.ContentType = *p.ContentType
+	}
+	if p.RunAt != nil {
+		merged.RunAt = *p.RunAt
+	}
+	if p.Retries != nil {
+		merged.Retries = *p.Retries
+	}
+	if p.Method != nil {
+		merged.Method = *p.Method
+	}
+	if p.Headers != nil {
+		merged.Headers = *p.Headers
+	}
+	if p.Query != nil {
+		merged.Query = *p.Query
+	}
+	if p.SuccessCriteria != nil {
+		merged.SuccessCriteria = p.SuccessCriteria
+	}
+	if p.RetryPolicy != nil {
+		merged.RetryPolicy = p.RetryPolicy
+	}
+	if err := merged.validateFields(); err != nil {
+		return job, err
+	}
+	// the current runAt may already be too close to be valid for a new job
+	if p.RunAt != nil {
+		if err := merged.validateRunAt(); err != nil {
+			return job, err
+		}
+		if !job.EndAt.IsZero() && merged.RunAt.After(job.EndAt) {
+			return job, ValidationError{"runAt must be before endAt"}
+		}
+	}
+	job.Name = merged.Name
+	job.Description = merged.Description
+	job.Url = merged.Url
+	job.Payload = merged.Payload
+	job.ContentType = merged.ContentType
+	job.RunAt = merged.RunAt.UTC()
+	job.Retries = merged.Retries
+	job.Method = normalizeMethod(merged.Method)
+	job.Headers = canonicalHeaders(merged.Headers)
+	job.Query = merged.Query
+	job.SuccessCriteria = ToSuccessCriteria(merged.SuccessCriteria)
+	job.RetryPolicy = ToRetryPolicy(merged.RetryPolicy)
+	// a new runAt starts the run over
+	if p.RunAt != nil {
+		job.Attempts = 0
+		job.RunScheduledAt = time.Time{}
+	}
+	return job, nil
+}
+
+func FromScheduledJob(job entities.ScheduledJob) ScheduledJobsPayload {
+	ans := ScheduledJobsPayload{
+		Name:        job.Name,
+		Description: job.Description,
+		Url:         job.Url,
+		Payload:     job.Payload,
+		ContentType: job.ContentType,
+		Signature:   job.Signature,
+		RunAt:       job.RunAt,
+		Retries:     job.Retries,
+		Method:      job.Method,
+		Headers:     job.Headers,
+		Query:       job.Query,
+		Schedule:    job.Schedule,
+		Timezone:    job.Timezone,
+		StartAt:     job.StartAt,
+		EndAt:       job.EndAt,
+		MaxRuns:     job.MaxRuns,
+		Tags:        job.Tags,
+		DedupKey:    job.DedupKey,
+	}
+	ans.SuccessCriteria = FromSuccessCriteria(job.SuccessCriteria)
+	ans.RetryPolicy = FromRetryPolicy(job.RetryPolicy)
+	if len(job.DedupKey) > 0 {
+		ans.DedupPolicy = job.DedupPolicy.String()
+	}
+	if len(job.Schedule) > 0 {
+		ans.DSTPolicy = job.DSTPolicy.String()
+	}
+	if job.Interval > 0 {
+		ans.Interval = job.Interval.String()
+	}
 	return ans
 }
 
@@ -112,14 +518,21 @@ func (h *ScheduledJobsHandler) Create(w http.ResponseWriter, r bunrouter.Request
 	if err := Bind(r, &p); err != nil {
 		return err
 	}
-	if err := p.Validate(); err != nil {
-		return err
-	}
-	job := ToScheduledJob(p)
 	currentUser, err := common.GetCurrentUser(r)
 	if err != nil {
 		return err
 	}
+	key := r.Header.Get("Idempotency-Key")
+	if len(key) == 0 {
+		key = p.IdempotencyKey
+	}
+	if len(key) > 0 {
+		return h.createIdempotent(w, r, currentUser, key, p)
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	job := ToScheduledJob(p)
 	job.UserID = currentUser.ID
 	job, err = h.srv.Schedule(r.Context(), job)
 	if err != nil {
@@ -129,57 +542,242 @@ func (h *ScheduledJobsHandler) Create(w http.ResponseWriter, r bunrouter.Request
 	return JSON(w, http.StatusCreated, resp)
 }
 
+// createIdempotent creates the job once per idempotency key. Retries of the
+// same request get the original response.
+func (h *ScheduledJobsHandler) createIdempotent(w http.ResponseWriter, r bunrouter.Request, u entities.User, key string, p ScheduledJobsPayload) error {
+	if len(key) > 255 {
+		return ValidationError{"idempotency key can be at most 255 characters"}
+	}
+	p.IdempotencyKey = ""
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	uid := uuid.New()
+	resp, err := json.Marshal(ScheduledJobResponse{uid.String()})
+	if err != nil {
+		return err
+	}
+	idempotencyKey := entities.IdempotencyKey{
+		UserID:      u.ID,
+		Key:         key,
+		RequestHash: cryptoutils.Sha256(string(b)),
+		StatusCode:  http.StatusCreated,
+		Response:    string(resp),
+		CreatedAt:   time.Now().UTC(),
+	}
+	stored, err := h.srv.ScheduleIdempotent(r.Context(), idempotencyKey, func() (entities.ScheduledJob, error) {
+		if err := p.Validate(); err != nil {
+			return entities.ScheduledJob{}, err
+		}
+		job := ToScheduledJob(p)
+		job.UID = uid
+		job.UserID = u.ID
+		return job, nil
+	})
+	if err != nil {
+		return err
+	}
+	if stored.Response != idempotencyKey.Response {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	return RawJSON(w, stored.StatusCode, []byte(stored.Response))
+}
+
 type ScheduledJobGetResponse struct {
 	UID         uuid.UUID           `json:"uid"`
 	Name        string              `json:"name"`
 	Description string              `json:"description"`
 	Url         string              `json:"url"`
+	Payload     string              `json:"payload"`
+	ContentType string              `json:"contentType"`
+	Signature   string              `json:"signature,omitempty"`
 	RunAt       time.Time           `json:"runAt"`
+	Retries     int                 `json:"retries"`
+	Method      string              `json:"method"`
+	Headers     map[string]string   `json:"headers,omitempty"`
+	Query       map[string]string   `json:"query,omitempty"`
+	Schedule    string              `json:"schedule,omitempty"`
+	Timezone    string              `json:"timezone,omitempty"`
+	DSTPolicy   string              `json:"dstPolicy,omitempty"`
+	Interval    string              `json:"interval,omitempty"`
+	StartAt     *time.Time          `json:"startAt,omitempty"`
+	EndAt       *time.Time          `json:"endAt,omitempty"`
+	MaxRuns     int                 `json:"maxRuns,omitempty"`
+	Runs        int                 `json:"runs"`
+	Tags        []string            `json:"tags"`
+	DedupKey    string              `json:"dedupKey,omitempty"`
+	DedupPolicy string              `json:"dedupPolicy,omitempty"`
 	Status      string              `json:"status"`
 	Executions  []ExecutionResponse `json:"executions"`
+
+	SuccessCriteria *SuccessCriteriaPayload `json:"successCriteria,omitempty"`
+	RetryPolicy     *RetryPolicyPayload     `json:"retryPolicy,omitempty"`
+	// Attempts counts the failed attempts of the current run
+	Attempts int `json:"attempts,omitempty"`
 }
 
+// ExecutionResponse is one attempt to deliver a run of a job. Attempt is 0
+// when the run failed before a request was sent.
 type ExecutionResponse struct {
-	StatusCode int       `json:"statusCode"`
-	Msg        string    `json:"msg"`
-	ExecutedAt time.Time `json:"executedAt"`
+	Run        int        `json:"run"`
+	Attempt    int        `json:"attempt"`
+	StatusCode int        `json:"statusCode"`
+	Msg        string     `json:"msg"`
+	ErrorClass string     `json:"errorClass,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	LatencyMs  int64      `json:"latencyMs"`
+	Worker     string     `json:"worker,omitempty"`
+	Partition  int        `json:"partition"`
+	// the response is kept for a limited time and its body is truncated
+	ResponseHeaders   map[string][]string `json:"responseHeaders,omitempty"`
+	ResponseBody      string              `json:"responseBody,omitempty"`
+	ResponseTruncated bool                `json:"responseTruncated,omitempty"`
+	ExecutedAt        time.Time           `json:"executedAt"`
+	// RetryAfter is set when the response asked for a later attempt
+	RetryAfter *time.Time `json:"retryAfter,omitempty"`
 }
 
-func (h *ScheduledJobsHandler) Get(w http.ResponseWriter, r bunrouter.Request) error {
-	uid := r.Param("uuid")
-	if len(uid) == 0 {
-		return ValidationError{"uuid is missing"}
+func ToExecutionResponse(e entities.Execution) ExecutionResponse {
+	ans := ExecutionResponse{
+		Run:               e.Run,
+		Attempt:           e.Attempt,
+		StatusCode:        e.StatusCode,
+		Msg:               e.Msg,
+		ErrorClass:        e.ErrorClass.String(),
+		LatencyMs:         e.Latency().Milliseconds(),
+		Worker:            e.Worker,
+		Partition:         e.Partition,
+		ResponseHeaders:   e.ResponseHeaders,
+		ResponseBody:      e.ResponseBody,
+		ResponseTruncated: e.ResponseTruncated,
+		ExecutedAt:        e.CreatedAt,
 	}
-	id, err := uuid.Parse(uid)
-	if err != nil {
-		return ValidationError{"not a valid uuid"}
+	if !e.StartedAt.IsZero() {
+		ans.StartedAt = &e.StartedAt
 	}
-	currentUser, err := common.GetCurrentUser(r)
-	if err != nil {
-		return err
+	if !e.FinishedAt.IsZero() {
+		ans.FinishedAt = &e.FinishedAt
 	}
-	job, executions, err := h.srv.Get(r.Context(), currentUser, id.String())
-	if err != nil {
-		return ErrNotFound
+	if !e.RetryAfter.IsZero() {
+		ans.RetryAfter = &e.RetryAfter
 	}
+	return ans
+}
+
+func ToScheduledJobGetResponse(job entities.ScheduledJob, executions []entities.Execution) ScheduledJobGetResponse {
 	ans := ScheduledJobGetResponse{
 		UID:         job.UID,
 		Name:        job.Name,
 		Description: job.Description,
 		Url:         job.Url,
+		Payload:     job.Payload,
+		ContentType: job.ContentType,
+		Signature:   job.Signature,
 		RunAt:       job.RunAt,
+		Retries:     job.Retries,
+		Method:      normalizeMethod(job.Method),
+		Headers:     job.Headers,
+		Query:       job.Query,
+		Schedule:    job.Schedule,
+		Timezone:    job.Timezone,
+		MaxRuns:     job.MaxRuns,
+		Runs:        job.Runs,
+		Tags:        job.Tags,
+		DedupKey:    job.DedupKey,
 		Status:      job.Status.String(),
 		Executions:  []ExecutionResponse{},
 	}
+	ans.SuccessCriteria = FromSuccessCriteria(job.SuccessCriteria)
+	ans.RetryPolicy = FromRetryPolicy(job.RetryPolicy)
+	ans.Attempts = job.Attempts
+	if ans.Tags == nil {
+		ans.Tags = []string{}
+	}
+	if len(job.DedupKey) > 0 {
+		ans.DedupPolicy = job.DedupPolicy.String()
+	}
+	if len(job.Schedule) > 0 {
+		ans.DSTPolicy = job.DSTPolicy.String()
+	}
+	if job.Interval > 0 {
+		ans.Interval = job.Interval.String()
+	}
+	if !job.StartAt.IsZero() {
+		ans.StartAt = &job.StartAt
+	}
+	if !job.EndAt.IsZero() {
+		ans.EndAt = &job.EndAt
+	}
 	for i := range executions {
-		ans.Executions = append(ans.Executions,
-			ExecutionResponse{
-				StatusCode: executions[i].StatusCode,
-				Msg:        executions[i].Msg,
-				ExecutedAt: executions[i].CreatedAt,
-			},
-		)
+		ans.Executions = append(ans.Executions, ToExecutionResponse(executions[i]))
+	}
+	return ans
+}
+
+func (h *ScheduledJobsHandler) Get(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, executions, err := h.srv.Get(r.Context(), currentUser, id.String())
+	if err != nil {
+		return ErrNotFound
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, executions))
+}
+
+// Delete cancels a job that has not started executing yet
+func (h *ScheduledJobsHandler) Delete(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, err := h.srv.Cancel(r.Context(), currentUser, id.String())
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, nil))
+}
+
+// Update edits a job that has not started executing yet
+func (h *ScheduledJobsHandler) Update(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	var p ScheduledJobsPatchPayload
+	if err := Bind(r, &p); err != nil {
+		return err
 	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, err := h.srv.Update(r.Context(), currentUser, id.String(), p.Apply)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, nil))
+}
 
-	return JSON(w, http.StatusOK, ans)
+func uuidParam(r bunrouter.Request) (uuid.UUID, error) {
+	uid := r.Param("uuid")
+	if len(uid) == 0 {
+		return uuid.UUID{}, ValidationError{"uuid is missing"}
+	}
+	id, err := uuid.Parse(uid)
+	if err != nil {
+		return uuid.UUID{}, ValidationError{"not a valid uuid"}
+	}
+	return id, nil
 }
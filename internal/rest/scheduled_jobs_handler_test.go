@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduledJobsPayloadRecurrence(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Minute)
+	base := ScheduledJobsPayload{
+		Name:        "job",
+		Url:         "https://example.com/wh",
+		ContentType: "application/json",
+	}
+	tests := []struct {
+		name    string
+		edit    func(p *ScheduledJobsPayload)
+		wantErr string
+	}{
+		{"interval anchored at startAt", func(p *ScheduledJobsPayload) {
+			p.Interval = "1h"
+			p.StartAt = now.Add(2 * time.Hour)
+		}, ""},
+		{"runAt before startAt", func(p *ScheduledJobsPayload) {
+			p.Interval = "1h"
+			p.StartAt = now.Add(2 * time.Hour)
+			p.RunAt = now.Add(time.Hour)
+		}, "runAt cannot be before startAt"},
+		{"cron with runAt before startAt", func(p *ScheduledJobsPayload) {
+			p.Schedule = "0 * * * *"
+			p.StartAt = now.Add(48 * time.Hour)
+			p.RunAt = now.Add(time.Hour)
+		}, "runAt cannot be before startAt"},
+		{"schedule without runs", func(p *ScheduledJobsPayload) {
+			p.Schedule = "0 0 1 1 *"
+			p.StartAt = now.Add(time.Hour)
+			p.EndAt = now.Add(2 * time.Hour)
+		}, "schedule has no runs between startAt and endAt"},
+	}
+	for _, tt := range tests {
+		p := base
+		tt.edit(&p)
+		err := p.Validate()
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFirstRun(t *testing.T) {
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	p := ScheduledJobsPayload{
+		Interval: "1h",
+		StartAt:  now.Add(-30 * time.Minute),
+	}
+	want := now.Add(30 * time.Minute)
+	if got := p.firstRun(now); !got.Equal(want) {
+		t.Fatalf("firstRun = %s, want %s", got, want)
+	}
+	p = ScheduledJobsPayload{Schedule: "15 12 * * *", Timezone: "Europe/Athens"}
+	want = time.Date(2024, 6, 1, 9, 15, 0, 0, time.UTC)
+	if got := p.firstRun(now.Add(-3 * time.Hour)); !got.Equal(want) {
+		t.Fatalf("firstRun = %s, want %s", got, want)
+	}
+}
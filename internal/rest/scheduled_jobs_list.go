@@ -0,0 +1,228 @@
+package rest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// ScheduledJobsFilterPayload selects jobs of the current user.
+// Empty fields do not restrict the selection.
+type ScheduledJobsFilterPayload struct {
+	Status      []string  `json:"status"`
+	NamePrefix  string    `json:"namePrefix"`
+	UrlHost     string    `json:"urlHost"`
+	Tags        []string  `json:"tags"`
+	RunAtFrom   time.Time `json:"runAtFrom"`
+	RunAtTo     time.Time `json:"runAtTo"`
+	CreatedFrom time.Time `json:"createdFrom"`
+	CreatedTo   time.Time `json:"createdTo"`
+}
+
+func (p ScheduledJobsFilterPayload) isEmpty() bool {
+	return len(p.Status) == 0 && len(p.NamePrefix) == 0 && len(p.UrlHost) == 0 &&
+		len(p.Tags) == 0 && p.RunAtFrom.IsZero() && p.RunAtTo.IsZero() &&
+		p.CreatedFrom.IsZero() && p.CreatedTo.IsZero()
+}
+
+func (p ScheduledJobsFilterPayload) ToFilter(userID int64) (entities.ScheduledJobFilter, error) {
+	ans := entities.ScheduledJobFilter{
+		UserID:      userID,
+		NamePrefix:  p.NamePrefix,
+		UrlHost:     p.UrlHost,
+		Tags:        p.Tags,
+		RunAtFrom:   p.RunAtFrom,
+		RunAtTo:     p.RunAtTo,
+		CreatedFrom: p.CreatedFrom,
+		CreatedTo:   p.CreatedTo,
+	}
+	for _, v := range p.Status {
+		st, err := entities.ParseScheduledJobStatus(v)
+		if err != nil {
+			return ans, ValidationError{err.Error()}
+		}
+		ans.Statuses = append(ans.Statuses, st)
+	}
+	if len(p.NamePrefix) > 32 {
+		return ans, ValidationError{"namePrefix cannot be more than 32 characters"}
+	}
+	if len(p.UrlHost) > 253 {
+		return ans, ValidationError{"urlHost cannot be more than 253 characters"}
+	}
+	if err := validateTags(p.Tags); err != nil {
+		return ans, err
+	}
+	return ans, nil
+}
+
+// filterFromQuery reads a filter from the query string. Lists are given
+// either comma separated or by repeating the parameter.
+func filterFromQuery(q url.Values) (ScheduledJobsFilterPayload, error) {
+	var (
+		ans ScheduledJobsFilterPayload
+		err error
+	)
+	ans.Status = queryList(q, "status")
+	ans.NamePrefix = q.Get("namePrefix")
+	ans.UrlHost = q.Get("urlHost")
+	ans.Tags = queryList(q, "tag")
+	times := []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"runAtFrom", &ans.RunAtFrom},
+		{"runAtTo", &ans.RunAtTo},
+		{"createdFrom", &ans.CreatedFrom},
+		{"createdTo", &ans.CreatedTo},
+	}
+	for _, t := range times {
+		v := q.Get(t.name)
+		if len(v) == 0 {
+			continue
+		}
+		*t.dst, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return ans, ValidationError{t.name + " must be an RFC3339 time"}
+		}
+	}
+	return ans, nil
+}
+
+func queryList(q url.Values, key string) []string {
+	var ans []string
+	for _, v := range q[key] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				ans = append(ans, item)
+			}
+		}
+	}
+	return ans
+}
+
+// listCursor is the opaque cursor handed to clients
+type listCursor struct {
+	RunAt time.Time `json:"r"`
+	ID    int64     `json:"i"`
+	Desc  bool      `json:"d,omitempty"`
+}
+
+func (c listCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeListCursor(s string) (listCursor, error) {
+	var c listCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ValidationError{"invalid cursor"}
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.ID <= 0 {
+		return c, ValidationError{"invalid cursor"}
+	}
+	return c, nil
+}
+
+// listPage is the page of jobs a list request asks for
+type listPage struct {
+	after entities.ScheduledJobCursor
+	limit int
+	desc  bool
+}
+
+// pageFromQuery reads the order, limit and cursor query parameters
+func pageFromQuery(q url.Values) (listPage, error) {
+	var (
+		ans listPage
+		err error
+	)
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		ans.desc = true
+	default:
+		return ans, ValidationError{"order must be asc or desc"}
+	}
+	ans.limit = defaultListLimit
+	if v := q.Get("limit"); len(v) > 0 {
+		ans.limit, err = strconv.Atoi(v)
+		if err != nil || ans.limit <= 0 || ans.limit > maxListLimit {
+			return ans, ValidationError{"limit must be between 1 and " + strconv.Itoa(maxListLimit)}
+		}
+	}
+	if v := q.Get("cursor"); len(v) > 0 {
+		c, err := decodeListCursor(v)
+		if err != nil {
+			return ans, err
+		}
+		if c.Desc != ans.desc {
+			return ans, ValidationError{"cursor was created with a different order"}
+		}
+		ans.after = entities.ScheduledJobCursor{RunAt: c.RunAt, ID: c.ID}
+	}
+	return ans, nil
+}
+
+// next returns the cursor of the page that follows the last job
+func (p listPage) next(last entities.ScheduledJob) string {
+	return listCursor{RunAt: last.RunAt, ID: last.ID, Desc: p.desc}.Encode()
+}
+
+type ScheduledJobListResponse struct {
+	Items      []ScheduledJobGetResponse `json:"items"`
+	NextCursor string                    `json:"nextCursor,omitempty"`
+}
+
+// List returns the jobs of the current user sorted by runAt.
+// Query parameters: status, namePrefix, urlHost, tag, runAtFrom, runAtTo,
+// createdFrom, createdTo, order (asc or desc), limit and cursor.
+func (h *ScheduledJobsHandler) List(w http.ResponseWriter, r bunrouter.Request) error {
+	q := r.URL.Query()
+	fp, err := filterFromQuery(q)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	filter, err := fp.ToFilter(currentUser.ID)
+	if err != nil {
+		return err
+	}
+	page, err := pageFromQuery(q)
+	if err != nil {
+		return err
+	}
+	// fetch one more to know if there is a next page
+	jobs, err := h.srv.List(r.Context(), filter, page.after, page.limit+1, page.desc)
+	if err != nil {
+		return err
+	}
+	ans := ScheduledJobListResponse{
+		Items: make([]ScheduledJobGetResponse, 0, len(jobs)),
+	}
+	if len(jobs) > page.limit {
+		jobs = jobs[:page.limit]
+		ans.NextCursor = page.next(jobs[len(jobs)-1])
+	}
+	for i := range jobs {
+		ans.Items = append(ans.Items, ToScheduledJobGetResponse(jobs[i], nil))
+	}
+	return JSON(w, http.StatusOK, ans)
+}
@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+// ResumePayload is the optional body of the resume endpoints
+type ResumePayload struct {
+	MissedRunPolicy string `json:"missedRunPolicy"`
+}
+
+type PauseAllResponse struct {
+	Affected int64 `json:"affected"`
+}
+
+// bindMissedRunPolicy reads the missed run policy of a resume request.
+// An empty body selects the default policy.
+func bindMissedRunPolicy(r bunrouter.Request) (entities.MissedRunPolicy, error) {
+	var p ResumePayload
+	if err := Bind(r, &p); err != nil && !errors.Is(err, io.EOF) {
+		return entities.MissedRunFire, err
+	}
+	policy, err := entities.ParseMissedRunPolicy(p.MissedRunPolicy)
+	if err != nil {
+		return policy, ValidationError{"missedRunPolicy must be one of fire, skip, fail"}
+	}
+	return policy, nil
+}
+
+// Pause stops a scheduled job from running until it is resumed
+func (h *ScheduledJobsHandler) Pause(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, err := h.srv.Pause(r.Context(), currentUser, id.String())
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, nil))
+}
+
+// Resume schedules a paused job again
+func (h *ScheduledJobsHandler) Resume(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	policy, err := bindMissedRunPolicy(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, err := h.srv.Resume(r.Context(), currentUser, id.String(), policy)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, nil))
+}
+
+// PauseAll pauses every scheduled job of the current user
+func (h *ScheduledJobsHandler) PauseAll(w http.ResponseWriter, r bunrouter.Request) error {
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	n, err := h.srv.PauseAll(r.Context(), currentUser)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, PauseAllResponse{Affected: n})
+}
+
+// ResumeAll resumes every paused job of the current user
+func (h *ScheduledJobsHandler) ResumeAll(w http.ResponseWriter, r bunrouter.Request) error {
+	policy, err := bindMissedRunPolicy(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	n, err := h.srv.ResumeAll(r.Context(), currentUser, policy)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, PauseAllResponse{Affected: n})
+}
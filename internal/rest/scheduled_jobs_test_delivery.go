@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+// TestDeliveryRequest is the request that a test delivery sent
+type TestDeliveryRequest struct {
+	Method  string              `json:"method"`
+	Url     string              `json:"url"`
+	Headers map[string][]string `json:"headers"`
+	Body    string              `json:"body"`
+}
+
+// TestDeliveryResponse holds the request of a test delivery and its outcome.
+// Success reports whether the response meets the success criteria of the job.
+type TestDeliveryResponse struct {
+	Request  TestDeliveryRequest `json:"request"`
+	Success  bool                `json:"success"`
+	Response ExecutionResponse   `json:"response"`
+}
+
+func ToTestDeliveryResponse(d entities.TestDelivery) TestDeliveryResponse {
+	ans := TestDeliveryResponse{
+		Request: TestDeliveryRequest{
+			Method:  d.Method,
+			Url:     d.Url,
+			Headers: d.Headers,
+			Body:    d.Body,
+		},
+		Success:  d.Execution.ErrorClass == entities.ErrorClassNone,
+		Response: ToExecutionResponse(d.Execution),
+	}
+	return ans
+}
+
+// TestDelivery sends the request of the job in the body right away, signed
+// like the scheduled deliveries, and returns it with the response. Nothing
+// is stored and the timing of the job is ignored.
+func (h *ScheduledJobsHandler) TestDelivery(w http.ResponseWriter, r bunrouter.Request) error {
+	var p ScheduledJobsPayload
+	if err := Bind(r, &p); err != nil {
+		return err
+	}
+	if err := p.validateFields(); err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job := ToScheduledJob(p)
+	job.UserID = currentUser.ID
+	d, err := h.srv.TestDelivery(r.Context(), currentUser, job)
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToTestDeliveryResponse(d))
+}
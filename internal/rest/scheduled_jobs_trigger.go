@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/gosom/hermeshooks/internal/common"
+)
+
+// Trigger runs a scheduled job now instead of at its runAt
+func (h *ScheduledJobsHandler) Trigger(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, err := h.srv.Trigger(r.Context(), currentUser, id.String())
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, nil))
+}
+
+// Replay sends the request of a job that succeeded or failed again now
+func (h *ScheduledJobsHandler) Replay(w http.ResponseWriter, r bunrouter.Request) error {
+	id, err := uuidParam(r)
+	if err != nil {
+		return err
+	}
+	currentUser, err := common.GetCurrentUser(r)
+	if err != nil {
+		return err
+	}
+	job, err := h.srv.Replay(r.Context(), currentUser, id.String())
+	if err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, ToScheduledJobGetResponse(job, nil))
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+// SuccessCriteriaPayload decides the outcome of the deliveries of a job.
+// Status codes are given like 404, 5xx or 500-504. By default 2xx are
+// successful and 408, 429 and 5xx are retried.
+type SuccessCriteriaPayload struct {
+	SuccessCodes   []string               `json:"successCodes,omitempty"`
+	RetryableCodes []string               `json:"retryableCodes,omitempty"`
+	Assertions     []BodyAssertionPayload `json:"assertions,omitempty"`
+}
+
+// BodyAssertionPayload checks the body of a successful response. With
+// jsonPath the value at the path must equal the JSON value in equals. With
+// contains the body must contain the string.
+type BodyAssertionPayload struct {
+	JSONPath string          `json:"jsonPath,omitempty"`
+	Equals   json.RawMessage `json:"equals,omitempty"`
+	Contains string          `json:"contains,omitempty"`
+}
+
+func (p *SuccessCriteriaPayload) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if len(p.SuccessCodes) > 20 || len(p.RetryableCodes) > 20 {
+		return ValidationError{"successCodes and retryableCodes can have at most 20 items"}
+	}
+	for _, codes := range [][]string{p.SuccessCodes, p.RetryableCodes} {
+		for _, code := range codes {
+			if _, err := entities.ParseStatusRange(code); err != nil {
+				return ValidationError{err.Error() + ", use a code like 404, a class like 5xx or a range like 500-504"}
+			}
+		}
+	}
+	if len(p.Assertions) > 10 {
+		return ValidationError{"a job can have at most 10 assertions"}
+	}
+	for _, a := range p.Assertions {
+		if err := a.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a BodyAssertionPayload) validate() error {
+	if len(a.JSONPath) == 0 && len(a.Contains) == 0 {
+		return ValidationError{"an assertion requires jsonPath or contains"}
+	}
+	if (len(a.JSONPath) == 0) != (len(a.Equals) == 0) {
+		return ValidationError{"jsonPath and equals must be used together"}
+	}
+	if len(a.JSONPath) > 256 {
+		return ValidationError{"jsonPath can be at most 256 characters"}
+	}
+	if len(a.JSONPath) > 0 {
+		if _, err := entities.ParseJSONPath(a.JSONPath); err != nil {
+			return ValidationError{err.Error() + `, use a path like $.data.items[0]["id"]`}
+		}
+	}
+	if len(a.Equals) > 1024 || len(a.Contains) > 1024 {
+		return ValidationError{"equals and contains can be at most 1024 characters"}
+	}
+	return nil
+}
+
+func ToSuccessCriteria(p *SuccessCriteriaPayload) entities.SuccessCriteria {
+	var ans entities.SuccessCriteria
+	if p == nil {
+		return ans
+	}
+	for _, code := range p.SuccessCodes {
+		r, _ := entities.ParseStatusRange(code)
+		ans.SuccessCodes = append(ans.SuccessCodes, r)
+	}
+	for _, code := range p.RetryableCodes {
+		r, _ := entities.ParseStatusRange(code)
+		ans.RetryableCodes = append(ans.RetryableCodes, r)
+	}
+	for _, a := range p.Assertions {
+		var equals bytes.Buffer
+		if len(a.Equals) > 0 {
+			json.Compact(&equals, a.Equals)
+		}
+		ans.Assertions = append(ans.Assertions, entities.BodyAssertion{
+			JSONPath: a.JSONPath,
+			Equals:   equals.String(),
+			Contains: a.Contains,
+		})
+	}
+	return ans
+}
+
+// FromSuccessCriteria returns nil when the job uses the default criteria
+func FromSuccessCriteria(c entities.SuccessCriteria) *SuccessCriteriaPayload {
+	if len(c.SuccessCodes) == 0 && len(c.RetryableCodes) == 0 && len(c.Assertions) == 0 {
+		return nil
+	}
+	var ans SuccessCriteriaPayload
+	for _, r := range c.SuccessCodes {
+		ans.SuccessCodes = append(ans.SuccessCodes, r.String())
+	}
+	for _, r := range c.RetryableCodes {
+		ans.RetryableCodes = append(ans.RetryableCodes, r.String())
+	}
+	for _, a := range c.Assertions {
+		ans.Assertions = append(ans.Assertions, BodyAssertionPayload{
+			JSONPath: a.JSONPath,
+			Equals:   json.RawMessage(a.Equals),
+			Contains: a.Contains,
+		})
+	}
+	return &ans
+}
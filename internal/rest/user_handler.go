@@ -17,7 +17,8 @@ type SignupPayload struct {
 }
 
 type SignupResponse struct {
-	Apikey string `json:"apiKey"`
+	Apikey        string `json:"apiKey"`
+	SigningSecret string `json:"signingSecret"`
 }
 
 func (o SignupPayload) Validate() error {
@@ -38,12 +39,13 @@ func (h *UserHandler) Create(w http.ResponseWriter, r bunrouter.Request) error {
 	if err := p.Validate(); err != nil {
 		return err
 	}
-	apiKey, err := h.srv.Signup(r.Context(), p.Username)
+	u, err := h.srv.Signup(r.Context(), p.Username)
 	if err != nil {
 		return err
 	}
 	ans := SignupResponse{
-		Apikey: apiKey,
+		Apikey:        u.ApiKey,
+		SigningSecret: u.SigningSecret,
 	}
 	return JSON(w, http.StatusCreated, ans)
 }
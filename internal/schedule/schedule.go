@@ -0,0 +1,223 @@
+package schedule
+
+import (
+	"errors"
+	"math/bits"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // the runtime image does not ship the tz database
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+const (
+	// MinFrequency is the shortest allowed time between two runs of a recurring job
+	MinFrequency = time.Minute
+
+	// maxDSTShift is larger than any daylight saving shift in the tz database
+	maxDSTShift = 3 * time.Hour
+	// maxIterations bounds the search for the next valid run
+	maxIterations = 1000
+	// starBit is set by the cron parser on fields given as *
+	starBit = 1 << 63
+)
+
+var parser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// Parse parses a standard cron expression. Both the 5 field format and the
+// 6 field format (with a leading seconds field) are accepted.
+func Parse(expr string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return nil, errors.New("use the timezone field instead of a TZ prefix")
+	}
+	if strings.HasPrefix(expr, "@every") {
+		return nil, errors.New("use the interval field instead of @every")
+	}
+	s, err := parser.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if s.Next(time.Now().UTC()).IsZero() {
+		return nil, errors.New("cron expression never fires")
+	}
+	// with a single second every firing falls in a different minute, with
+	// more than one some minute fires twice, so checking the seconds field
+	// covers every gap of the schedule and not only the next one
+	if spec, ok := s.(*cron.SpecSchedule); ok && bits.OnesCount64(spec.Second&^starBit) > 1 {
+		return nil, errors.New("cron expression cannot fire more than once per minute")
+	}
+	return s, nil
+}
+
+// LoadLocation returns the IANA time zone with the given name.
+// An empty name means UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if len(name) == 0 {
+		return time.UTC, nil
+	}
+	if strings.EqualFold(name, "local") {
+		return nil, errors.New("local is not a valid time zone")
+	}
+	return time.LoadLocation(name)
+}
+
+// Next returns the next time a recurring job should run strictly after the
+// given time, honoring the startAt, endAt and maxRuns bounds of the job.
+// The boolean is false when the job is not recurring or has no more runs.
+func Next(job entities.ScheduledJob, after time.Time) (time.Time, bool) {
+	if !job.IsRecurring() {
+		return time.Time{}, false
+	}
+	if job.MaxRuns > 0 && job.Runs >= job.MaxRuns {
+		return time.Time{}, false
+	}
+	if !job.StartAt.IsZero() && after.Before(job.StartAt) {
+		after = job.StartAt.Add(-time.Nanosecond)
+	}
+	var (
+		next time.Time
+		ok   bool
+	)
+	if job.Interval > 0 {
+		next, ok = nextInterval(job, after)
+	} else {
+		next, ok = nextCron(job, after)
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+	if !job.EndAt.IsZero() && next.After(job.EndAt) {
+		return time.Time{}, false
+	}
+	return next.UTC(), true
+}
+
+// nextInterval returns the next run of a fixed interval job. Runs are
+// anchored at startAt so they do not drift with execution delays.
+func nextInterval(job entities.ScheduledJob, after time.Time) (time.Time, bool) {
+	anchor := job.StartAt
+	if anchor.IsZero() {
+		return after.Add(job.Interval), true
+	}
+	if after.Before(anchor) {
+		return anchor, true
+	}
+	n := after.Sub(anchor)/job.Interval + 1
+	return anchor.Add(n * job.Interval), true
+}
+
+func nextCron(job entities.ScheduledJob, after time.Time) (time.Time, bool) {
+	s, err := Parse(job.Schedule)
+	if err != nil {
+		return time.Time{}, false
+	}
+	loc, err := LoadLocation(job.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return nextInLocation(s, loc, job.DSTPolicy, after)
+}
+
+// nextInLocation matches the cron fields against the wall clock of loc.
+// The schedule is evaluated on a floating clock (UTC without offsets) and each
+// candidate is mapped back to the real instants it denotes in loc, so that
+// daylight saving gaps and overlaps are resolved by the policy.
+func nextInLocation(s cron.Schedule, loc *time.Location, policy entities.DSTPolicy, after time.Time) (time.Time, bool) {
+	// start a bit earlier since during an overlap the wall clock goes back
+	wall := wallClock(after.In(loc)).Add(-maxDSTShift)
+	for i := 0; i < maxIterations; i++ {
+		wall = s.Next(wall)
+		if wall.IsZero() {
+			return time.Time{}, false
+		}
+		instants := fromWallClock(wall, loc)
+		switch {
+		case len(instants) == 0: // the wall clock time falls in a gap
+			if policy == entities.DSTSkip {
+				continue
+			}
+			if t := shiftedFromGap(wall, loc); t.After(after) {
+				return t, true
+			}
+		case policy == entities.DSTRunTwice:
+			for _, t := range instants {
+				if t.After(after) {
+					return t, true
+				}
+			}
+		default:
+			if instants[0].After(after) {
+				return instants[0], true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func wallClock(t time.Time) time.Time {
+	return time.Date(
+		t.Year(), t.Month(), t.Day(),
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(),
+		time.UTC,
+	)
+}
+
+// zoneOffsets returns the distinct offsets of loc around the wall clock time
+func zoneOffsets(wall time.Time, loc *time.Location) []int {
+	probe := time.Date(
+		wall.Year(), wall.Month(), wall.Day(),
+		wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(),
+		loc,
+	)
+	var offsets []int
+	for _, d := range []time.Duration{-12 * time.Hour, 0, 12 * time.Hour} {
+		_, offset := probe.Add(d).Zone()
+		found := false
+		for _, o := range offsets {
+			if o == offset {
+				found = true
+				break
+			}
+		}
+		if !found {
+			offsets = append(offsets, offset)
+		}
+	}
+	return offsets
+}
+
+// fromWallClock returns the instants, in ascending order, at which the wall
+// clock of loc shows the given time. It returns no instants when the time
+// falls in a gap and two when it falls in an overlap.
+func fromWallClock(wall time.Time, loc *time.Location) []time.Time {
+	var ans []time.Time
+	for _, offset := range zoneOffsets(wall, loc) {
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if wallClock(t).Equal(wall) {
+			ans = append(ans, t)
+		}
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		return ans[i].Before(ans[j])
+	})
+	return ans
+}
+
+// shiftedFromGap maps a wall clock time that falls in a gap to the instant
+// after the gap using the offset in effect before the transition.
+func shiftedFromGap(wall time.Time, loc *time.Location) time.Time {
+	offsets := zoneOffsets(wall, loc)
+	before := offsets[0]
+	for _, o := range offsets[1:] {
+		if o < before {
+			before = o
+		}
+	}
+	return wall.Add(-time.Duration(before) * time.Second).In(loc)
+}
@@ -0,0 +1,160 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t.UTC()
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"0 9 * * *", false},
+		{"30 0 9 * * *", false},
+		{"@daily", false},
+		{"*/5 * * * *", false},
+		{"* * * * * *", true},
+		{"*/30 * * * * *", true},
+		{"0,30 0 9 1 * *", true},
+		{"0,59 0 9 * * *", true},
+		{"59 * * * * *", false},
+		{"@every 1h", true},
+		{"TZ=Europe/Athens 0 9 * * *", true},
+		{"CRON_TZ=UTC 0 9 * * *", true},
+		{"0 0 30 2 *", true},
+		{"not a cron", true},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNextCronDST(t *testing.T) {
+	// Europe/Athens moves from +02:00 to +03:00 at 2024-03-31 03:00 local
+	// time and back at 2024-10-27 04:00, so 03:30 does not exist on the
+	// first day and happens twice on the second
+	tests := []struct {
+		name   string
+		policy entities.DSTPolicy
+		after  string
+		want   string
+	}{
+		{"gap runs once after the gap", entities.DSTRunOnce, "2024-03-30T12:00:00Z", "2024-03-31T01:30:00Z"},
+		{"gap is skipped", entities.DSTSkip, "2024-03-30T12:00:00Z", "2024-04-01T00:30:00Z"},
+		{"gap runs after the gap with twice", entities.DSTRunTwice, "2024-03-30T12:00:00Z", "2024-03-31T01:30:00Z"},
+		{"overlap runs at the first instant", entities.DSTRunOnce, "2024-10-26T12:00:00Z", "2024-10-27T00:30:00Z"},
+		{"overlap runs once", entities.DSTRunOnce, "2024-10-27T00:30:00Z", "2024-10-28T01:30:00Z"},
+		{"overlap runs once with skip", entities.DSTSkip, "2024-10-27T00:30:00Z", "2024-10-28T01:30:00Z"},
+		{"overlap runs twice", entities.DSTRunTwice, "2024-10-27T00:30:00Z", "2024-10-27T01:30:00Z"},
+		{"overlap ends after the second run", entities.DSTRunTwice, "2024-10-27T01:30:00Z", "2024-10-28T01:30:00Z"},
+		{"normal day", entities.DSTRunOnce, "2024-06-01T12:00:00Z", "2024-06-02T00:30:00Z"},
+	}
+	for _, tt := range tests {
+		job := entities.ScheduledJob{
+			Schedule:  "30 3 * * *",
+			Timezone:  "Europe/Athens",
+			DSTPolicy: tt.policy,
+		}
+		got, ok := Next(job, utc(tt.after))
+		if !ok || !got.Equal(utc(tt.want)) {
+			t.Errorf("%s: Next = %s, %v, want %s", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestNextInterval(t *testing.T) {
+	anchor := utc("2024-01-01T10:00:00Z")
+	tests := []struct {
+		name  string
+		after time.Time
+		want  time.Time
+	}{
+		{"before the anchor", anchor.Add(-time.Hour), anchor},
+		{"at the anchor", anchor, anchor.Add(time.Hour)},
+		{"between runs", anchor.Add(90 * time.Minute), anchor.Add(2 * time.Hour)},
+		{"at a run", anchor.Add(2 * time.Hour), anchor.Add(3 * time.Hour)},
+		{"late execution does not drift", anchor.Add(3*time.Hour + 59*time.Minute), anchor.Add(4 * time.Hour)},
+	}
+	for _, tt := range tests {
+		job := entities.ScheduledJob{Interval: time.Hour, StartAt: anchor}
+		got, ok := Next(job, tt.after)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s: Next = %s, %v, want %s", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestNextBounds(t *testing.T) {
+	start := utc("2024-01-01T00:00:00Z")
+	tests := []struct {
+		name   string
+		job    entities.ScheduledJob
+		after  time.Time
+		want   time.Time
+		wantOk bool
+	}{
+		{
+			name:  "not recurring",
+			job:   entities.ScheduledJob{RunAt: start},
+			after: start,
+		},
+		{
+			name:  "maxRuns reached",
+			job:   entities.ScheduledJob{Schedule: "0 * * * *", MaxRuns: 3, Runs: 3},
+			after: start,
+		},
+		{
+			name:   "maxRuns not reached",
+			job:    entities.ScheduledJob{Schedule: "0 * * * *", MaxRuns: 3, Runs: 2},
+			after:  start,
+			want:   start.Add(time.Hour),
+			wantOk: true,
+		},
+		{
+			name:   "endAt is inclusive",
+			job:    entities.ScheduledJob{Schedule: "0 * * * *", EndAt: start.Add(time.Hour)},
+			after:  start,
+			want:   start.Add(time.Hour),
+			wantOk: true,
+		},
+		{
+			name:  "after endAt",
+			job:   entities.ScheduledJob{Schedule: "0 * * * *", EndAt: start.Add(90 * time.Minute)},
+			after: start.Add(time.Hour),
+		},
+		{
+			name:   "startAt is inclusive",
+			job:    entities.ScheduledJob{Schedule: "0 * * * *", StartAt: start.Add(24 * time.Hour)},
+			after:  start,
+			want:   start.Add(24 * time.Hour),
+			wantOk: true,
+		},
+		{
+			name:   "interval without anchor",
+			job:    entities.ScheduledJob{Interval: 15 * time.Minute},
+			after:  start,
+			want:   start.Add(15 * time.Minute),
+			wantOk: true,
+		},
+	}
+	for _, tt := range tests {
+		got, ok := Next(tt.job, tt.after)
+		if ok != tt.wantOk || !got.Equal(tt.want) {
+			t.Errorf("%s: Next = %s, %v, want %s, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -22,21 +22,29 @@ type Config struct {
 	DB             *storage.DB
 	RapidApiKey    string
 	InternalApiKey string
+	// SigningSecretGrace is how long the previous signing secret keeps
+	// signing requests after a rotation
+	SigningSecretGrace time.Duration
 }
 
 type AuthService struct {
-	log            zerolog.Logger
-	db             *storage.DB
-	rapidApiKey    string
-	internalApiKey string
+	log                zerolog.Logger
+	db                 *storage.DB
+	rapidApiKey        string
+	internalApiKey     string
+	signingSecretGrace time.Duration
 }
 
 func New(cfg Config) (*AuthService, error) {
+	if cfg.SigningSecretGrace == 0 {
+		cfg.SigningSecretGrace = 24 * time.Hour
+	}
 	ans := AuthService{
-		log:            cfg.Log,
-		db:             cfg.DB,
-		rapidApiKey:    cfg.RapidApiKey,
-		internalApiKey: cfg.InternalApiKey,
+		log:                cfg.Log,
+		db:                 cfg.DB,
+		rapidApiKey:        cfg.RapidApiKey,
+		internalApiKey:     cfg.InternalApiKey,
+		signingSecretGrace: cfg.SigningSecretGrace,
 	}
 	return &ans, nil
 }
@@ -102,6 +110,7 @@ func (a *AuthService) rapidApiAuth(req bunrouter.Request) (u entities.User, err
 			return user, tx.Commit()
 		default:
 			user.Username = username
+			user.SigningSecret = cryptoutils.SigningSecret()
 			user.CreatedAt = time.Now().UTC()
 			user, err = storage.InsertUser(ctx, tx, user)
 			if err != nil {
@@ -137,15 +146,29 @@ func (a *AuthService) InternalApi(next bunrouter.HandlerFunc) bunrouter.HandlerF
 	}
 }
 
-func (a *AuthService) Signup(ctx context.Context, username string) (string, error) {
-	apiKey := cryptoutils.XApiKey()
+// Signup creates a user and returns it with the api key and the signing
+// secret in plain text. Only the hash of the api key is stored.
+func (a *AuthService) Signup(ctx context.Context, username string) (entities.User, error) {
 	u := entities.User{
-		Username:  username,
-		ApiKey:    apiKey,
-		CreatedAt: time.Now(),
+		Username:      username,
+		ApiKey:        cryptoutils.XApiKey(),
+		SigningSecret: cryptoutils.SigningSecret(),
+		CreatedAt:     time.Now(),
 	}
 	_, err := storage.InsertUser(ctx, a.db, u)
-	return u.ApiKey, err
+	return u, err
+}
+
+// RotateSigningSecret replaces the signing secret of the user. The replaced
+// secret keeps signing requests, next to the new one, for the grace period.
+func (a *AuthService) RotateSigningSecret(ctx context.Context, u entities.User) (entities.User, error) {
+	u.PreviousSigningSecret = u.SigningSecret
+	u.PreviousSigningSecretExpiresAt = time.Now().UTC().Add(a.signingSecretGrace)
+	u.SigningSecret = cryptoutils.SigningSecret()
+	if err := storage.UpdateUserSigningSecrets(ctx, a.db, u); err != nil {
+		return u, err
+	}
+	return u, nil
 }
 
 func toJSON(w http.ResponseWriter, statusCode int, value interface{}) error {
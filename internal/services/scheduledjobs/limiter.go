@@ -0,0 +1,58 @@
+package scheduledjobs
+
+import (
+	"sync"
+	"time"
+)
+
+// userLimiter allows up to limit calls per user in every window. The
+// windows are kept in memory, so every server limits on its own.
+type userLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[int64]limiterWindow
+}
+
+type limiterWindow struct {
+	start time.Time
+	count int
+}
+
+func newUserLimiter(limit int, window time.Duration) *userLimiter {
+	ans := userLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[int64]limiterWindow),
+	}
+	return &ans
+}
+
+// allow counts a call of the user and reports whether it is within the
+// limit
+func (l *userLimiter) allow(userID int64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w, ok := l.windows[userID]
+	if !ok || now.Sub(w.start) >= l.window {
+		if len(l.windows) >= 1024 {
+			l.sweep(now)
+		}
+		w = limiterWindow{start: now}
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	l.windows[userID] = w
+	return true
+}
+
+// sweep removes the windows that ended
+func (l *userLimiter) sweep(now time.Time) {
+	for id, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, id)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package scheduledjobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newUserLimiter(2, time.Minute)
+
+	steps := []struct {
+		userID int64
+		at     time.Duration
+		want   bool
+	}{
+		{1, 0, true},
+		{1, time.Second, true},
+		{1, 2 * time.Second, false},
+		{2, 2 * time.Second, true},
+		{1, 59 * time.Second, false},
+		{1, time.Minute, true},
+		{1, time.Minute + time.Second, true},
+		{1, time.Minute + 2*time.Second, false},
+	}
+	for i, s := range steps {
+		if got := l.allow(s.userID, now.Add(s.at)); got != s.want {
+			t.Errorf("step %d: allow(%d, +%s) = %v, want %v", i, s.userID, s.at, got, s.want)
+		}
+	}
+}
@@ -2,13 +2,34 @@ package scheduledjobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
+	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/delivery"
 	"github.com/gosom/hermeshooks/internal/entities"
+	"github.com/gosom/hermeshooks/internal/schedule"
 	"github.com/gosom/hermeshooks/internal/storage"
 )
 
+const (
+	// bulkBatchSize is the number of rows a bulk operation changes per transaction
+	bulkBatchSize = 500
+	// bulkOperationTimeout bounds the duration of a bulk operation
+	bulkOperationTimeout = 10 * time.Minute
+	// testDeliveryTimeout bounds the duration of a test delivery
+	testDeliveryTimeout = 10 * time.Second
+	// testDeliveryBodyLimit is the number of bytes of the response body
+	// that a test delivery returns
+	testDeliveryBodyLimit = 4 << 10
+	// testDeliveryWindow is the window of TestDeliveryRate
+	testDeliveryWindow = time.Minute
+)
+
 type Partitioner interface {
 	RLock()
 	Pick() int
@@ -16,32 +37,803 @@ type Partitioner interface {
 }
 
 type ServiceConfig struct {
-	Log         zerolog.Logger
-	DB          *storage.DB
-	Partitioner Partitioner
+	Log                  zerolog.Logger
+	DB                   *storage.DB
+	Partitioner          Partitioner
+	IdempotencyRetention time.Duration
+	// ResponseRetention is how long the response headers and bodies of
+	// the executions are kept
+	ResponseRetention time.Duration
+	// Signer and TestClient sign and send the test deliveries. The
+	// default client only connects to public addresses.
+	Signer     delivery.Signer
+	TestClient common.HTTPClient
+	// TestDeliveryRate is how many test deliveries a user can send per
+	// minute
+	TestDeliveryRate int
 }
 
 type Service struct {
-	log         zerolog.Logger
-	db          *storage.DB
-	partitioner Partitioner
+	log                  zerolog.Logger
+	db                   *storage.DB
+	partitioner          Partitioner
+	idempotencyRetention time.Duration
+	responseRetention    time.Duration
+	signer               delivery.Signer
+	testClient           common.HTTPClient
+	testLimiter          *userLimiter
 }
 
 func New(cfg ServiceConfig) *Service {
+	if cfg.IdempotencyRetention == 0 {
+		cfg.IdempotencyRetention = 24 * time.Hour
+	}
+	if cfg.ResponseRetention == 0 {
+		cfg.ResponseRetention = 7 * 24 * time.Hour
+	}
+	if cfg.TestClient == nil {
+		cfg.TestClient = delivery.NewRestrictedClient(testDeliveryTimeout)
+	}
+	if cfg.TestDeliveryRate == 0 {
+		cfg.TestDeliveryRate = 10
+	}
 	ans := Service{
-		log:         cfg.Log,
-		db:          cfg.DB,
-		partitioner: cfg.Partitioner,
+		log:                  cfg.Log,
+		db:                   cfg.DB,
+		partitioner:          cfg.Partitioner,
+		idempotencyRetention: cfg.IdempotencyRetention,
+		responseRetention:    cfg.ResponseRetention,
+		signer:               cfg.Signer,
+		testClient:           cfg.TestClient,
+		testLimiter:          newUserLimiter(cfg.TestDeliveryRate, testDeliveryWindow),
 	}
 	return &ans
 }
 
 func (s *Service) Schedule(ctx context.Context, job entities.ScheduledJob) (entities.ScheduledJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return job, err
+	}
+	defer tx.Rollback()
+	job, err = s.schedule(ctx, tx, job)
+	if err != nil {
+		return job, err
+	}
+	return job, tx.Commit()
+}
+
+// schedule inserts the job in a random partition, paused when the account
+// of the user is paused. When the job has a dedup key, an existing job with
+// the same key is either replaced or the new job is rejected, depending on
+// the dedup policy of the new job.
+func (s *Service) schedule(ctx context.Context, db storage.IDB, job entities.ScheduledJob) (entities.ScheduledJob, error) {
+	paused, err := storage.IsUserPausedForShare(ctx, db, job.UserID)
+	if err != nil {
+		return job, err
+	}
+	if paused {
+		job.Status = entities.Paused
+	}
+	cancelled, err := s.cancelDuplicate(ctx, db, job)
+	if err != nil {
+		return job, err
+	}
+	if cancelled.ID > 0 {
+		if err := storage.Notify(ctx, db, map[string]int{
+			"partition": cancelled.Partition,
+		}); err != nil {
+			return job, err
+		}
+	}
+	job.Partition = s.pick()
+	return storage.InsertScheduledJob(ctx, db, job)
+}
+
+// cancelDuplicate applies the dedup policy of the job. It returns the job
+// that was cancelled in favor of the new one, if any. A pending job is
+// running and cannot be cancelled, so it is superseded instead: its attempt
+// finishes but it is not retried or run again.
+func (s *Service) cancelDuplicate(ctx context.Context, db storage.IDB, job entities.ScheduledJob) (entities.ScheduledJob, error) {
+	if len(job.DedupKey) == 0 {
+		return entities.ScheduledJob{}, nil
+	}
+	existing, err := storage.GetJobByDedupKeyForUpdate(ctx, db, job.UserID, job.DedupKey)
+	switch {
+	case errors.Is(err, entities.ErrJobNotFound):
+		return entities.ScheduledJob{}, nil
+	case err != nil:
+		return entities.ScheduledJob{}, err
+	case job.DedupPolicy == entities.DedupReject:
+		return entities.ScheduledJob{}, fmt.Errorf("%w: %s", entities.ErrDuplicateJob, existing.UID)
+	case existing.Status == entities.Pending:
+		return entities.ScheduledJob{}, storage.SupersedeJob(ctx, db, existing.ID, time.Now().UTC())
+	}
+	existing.Status = entities.Deleted
+	existing.UpdatedAt = time.Now().UTC()
+	if err := storage.UpdateJobStatus(ctx, db, existing); err != nil {
+		return entities.ScheduledJob{}, err
+	}
+	return existing, nil
+}
+
+func (s *Service) pick() int {
 	s.partitioner.RLock()
 	defer s.partitioner.RUnlock()
-	job.Partition = s.partitioner.Pick()
-	return storage.InsertScheduledJob(ctx, s.db, job)
+	return s.partitioner.Pick()
+}
+
+// ScheduleBatch schedules the jobs in a single transaction. Jobs rejected
+// because of their dedup key get an error at the same index of the returned
+// errors and the rest are scheduled. Every affected partition is notified once.
+func (s *Service) ScheduleBatch(ctx context.Context, jobs []entities.ScheduledJob) ([]entities.ScheduledJob, []error, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	errs := make([]error, len(jobs))
+	paused := make(map[int64]bool)
+	var partitions []int
+	toInsert := make([]entities.ScheduledJob, 0, len(jobs))
+	positions := make([]int, 0, len(jobs))
+	for i := range jobs {
+		p, ok := paused[jobs[i].UserID]
+		if !ok {
+			if p, err = storage.IsUserPausedForShare(ctx, tx, jobs[i].UserID); err != nil {
+				return nil, nil, err
+			}
+			paused[jobs[i].UserID] = p
+		}
+		if p {
+			jobs[i].Status = entities.Paused
+		}
+		cancelled, err := s.cancelDuplicate(ctx, tx, jobs[i])
+		switch {
+		case errors.Is(err, entities.ErrDuplicateJob):
+			errs[i] = err
+			continue
+		case err != nil:
+			return nil, nil, err
+		}
+		if cancelled.ID > 0 {
+			partitions = append(partitions, cancelled.Partition)
+		}
+		jobs[i].Partition = s.pick()
+		partitions = append(partitions, jobs[i].Partition)
+		toInsert = append(toInsert, jobs[i])
+		positions = append(positions, i)
+	}
+	inserted, err := storage.InsertScheduledJobs(ctx, tx, toInsert)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, i := range positions {
+		jobs[i] = inserted[k]
+	}
+	if err := notifyPartitions(ctx, tx, partitions); err != nil {
+		return nil, nil, err
+	}
+	return jobs, errs, tx.Commit()
+}
+
+// ScheduleIdempotent schedules the job that build returns at most once per
+// idempotency key within the retention window. Repeated requests get back the
+// stored key with the original response, while reusing a key for a different
+// request fails with ErrIdempotencyKeyMismatch. build is only called for keys
+// that have not been used.
+func (s *Service) ScheduleIdempotent(ctx context.Context, key entities.IdempotencyKey, build func() (entities.ScheduledJob, error)) (entities.IdempotencyKey, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return key, err
+	}
+	defer tx.Rollback()
+	expired := time.Now().UTC().Add(-s.idempotencyRetention)
+	if _, err := storage.DeleteIdempotencyKeys(ctx, tx, expired, key.UserID, key.Key); err != nil {
+		return key, err
+	}
+	inserted, err := storage.InsertIdempotencyKey(ctx, tx, key)
+	if err != nil {
+		return key, err
+	}
+	if !inserted {
+		stored, err := storage.GetIdempotencyKey(ctx, tx, key.UserID, key.Key)
+		if err != nil {
+			return key, err
+		}
+		if stored.RequestHash != key.RequestHash {
+			return stored, entities.ErrIdempotencyKeyMismatch
+		}
+		return stored, tx.Commit()
+	}
+	job, err := build()
+	if err != nil {
+		return key, err
+	}
+	if _, err := s.schedule(ctx, tx, job); err != nil {
+		return key, err
+	}
+	return key, tx.Commit()
 }
+
+// StartIdempotencyKeysCleaner periodically deletes the idempotency keys
+// that are older than the retention window.
+func (s *Service) StartIdempotencyKeysCleaner(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired := time.Now().UTC().Add(-s.idempotencyRetention)
+			n, err := storage.DeleteIdempotencyKeys(ctx, s.db, expired, 0, "")
+			if err != nil {
+				s.log.Error().Err(err).Msg("cannot delete expired idempotency keys")
+				continue
+			}
+			s.log.Info().Int64("count", n).Msg("deleted expired idempotency keys")
+		}
+	}
+}
+
+// StartOperationsJanitor periodically fails the bulk operations that are
+// still running after bulkOperationTimeout, like the ones of a server that
+// stopped while running them
+func (s *Service) StartOperationsJanitor(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// a running operation finishes within bulkOperationTimeout
+			// and stores its outcome right after
+			before := time.Now().UTC().Add(-bulkOperationTimeout - time.Minute)
+			n, err := storage.FailInterruptedBulkOperations(ctx, s.db, before)
+			if err != nil {
+				s.log.Error().Err(err).Msg("cannot fail interrupted bulk operations")
+				continue
+			}
+			if n > 0 {
+				s.log.Info().Int64("count", n).Msg("failed interrupted bulk operations")
+			}
+		}
+	}
+}
+
+// StartResponsesCleaner periodically clears the response headers and
+// bodies of the executions that are older than the response retention
+func (s *Service) StartResponsesCleaner(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired := time.Now().UTC().Add(-s.responseRetention)
+			n, err := storage.ClearExecutionResponses(ctx, s.db, expired)
+			if err != nil {
+				s.log.Error().Err(err).Msg("cannot clear expired execution responses")
+				continue
+			}
+			s.log.Info().Int64("count", n).Msg("cleared expired execution responses")
+		}
+	}
+}
+
+// List returns the jobs matching the filter that come after the cursor
+func (s *Service) List(ctx context.Context, f entities.ScheduledJobFilter, after entities.ScheduledJobCursor, limit int, desc bool) ([]entities.ScheduledJob, error) {
+	return storage.ListScheduledJobs(ctx, s.db, f, after, limit, desc)
+}
+
+// CancelMatching starts a background operation that moves every scheduled
+// or paused job matching the filter to Deleted. The returned operation can be polled
+// with GetOperation.
+func (s *Service) CancelMatching(ctx context.Context, f entities.ScheduledJobFilter) (entities.BulkOperation, error) {
+	now := time.Now().UTC()
+	op := entities.BulkOperation{
+		UID:       uuid.New(),
+		UserID:    f.UserID,
+		Kind:      "cancel",
+		Status:    entities.OperationRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	op, err := storage.InsertBulkOperation(ctx, s.db, op)
+	if err != nil {
+		return op, err
+	}
+	go s.runBulkOperation(op, func(ctx context.Context) (int, bool, error) {
+		n, err := s.cancelBatch(ctx, f)
+		return n, n == bulkBatchSize, err
+	})
+	return op, nil
+}
+
+// runBulkOperation calls batch until it reports that no jobs are left and
+// stores the outcome in the operation. batch returns how many jobs it
+// changed and whether more jobs may match.
+func (s *Service) runBulkOperation(op entities.BulkOperation, batch func(ctx context.Context) (int, bool, error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkOperationTimeout)
+	defer cancel()
+	var err error
+	for {
+		var (
+			n    int
+			more bool
+		)
+		if n, more, err = batch(ctx); err != nil {
+			break
+		}
+		op.Affected += int64(n)
+		if !more {
+			break
+		}
+		// report the progress to clients polling the operation
+		op.UpdatedAt = time.Now().UTC()
+		if err = storage.UpdateBulkOperation(ctx, s.db, op); err != nil {
+			break
+		}
+	}
+	op.Status = entities.OperationDone
+	if err != nil {
+		op.Status = entities.OperationFailed
+		op.Error = err.Error()
+		s.log.Error().Err(err).Str("operation", op.UID.String()).Msg("bulk " + op.Kind + " failed")
+	}
+	op.UpdatedAt = time.Now().UTC()
+	// the operation context may have expired
+	if err := storage.UpdateBulkOperation(context.Background(), s.db, op); err != nil {
+		s.log.Error().Err(err).Str("operation", op.UID.String()).Msg("cannot update bulk operation")
+	}
+}
+
+// cancelBatch cancels at most bulkBatchSize jobs and notifies the affected
+// partitions once each.
+func (s *Service) cancelBatch(ctx context.Context, f entities.ScheduledJobFilter) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	partitions, err := storage.CancelScheduledJobs(ctx, tx, f, bulkBatchSize, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if err := notifyPartitions(ctx, tx, partitions); err != nil {
+		return 0, err
+	}
+	return len(partitions), tx.Commit()
+}
+
+// ListDeadLetters returns the failed jobs matching the filter that come after
+// the cursor, with the executions of their last run
+func (s *Service) ListDeadLetters(ctx context.Context, f entities.ScheduledJobFilter, after entities.ScheduledJobCursor, limit int, desc bool) ([]entities.DeadLetter, error) {
+	f.Statuses = []entities.ScheduledJobStatus{entities.Fail}
+	jobs, err := storage.ListScheduledJobs(ctx, s.db, f, after, limit, desc)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(jobs))
+	for i := range jobs {
+		ids[i] = jobs[i].ID
+	}
+	executions, err := storage.SelectLastRunExecutions(ctx, s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	ans := make([]entities.DeadLetter, len(jobs))
+	for i := range jobs {
+		ans[i] = entities.DeadLetter{Job: jobs[i], Executions: executions[jobs[i].ID]}
+	}
+	return ans, nil
+}
+
+// Redrive starts a new run of a failed job. The executions of its previous
+// runs are kept.
+func (s *Service) Redrive(ctx context.Context, u entities.User, uid string, r entities.Redrive) (entities.ScheduledJob, error) {
+	return s.rerun(ctx, u, uid, r, func(job entities.ScheduledJob) error {
+		if job.Status != entities.Fail {
+			return fmt.Errorf("%w: cannot redrive a %s job", entities.ErrJobNotFailed, job.Status)
+		}
+		return nil
+	})
+}
+
+// Replay starts a new run of a job that succeeded or failed which sends the
+// same request immediately. The executions of its previous runs are kept.
+func (s *Service) Replay(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error) {
+	return s.rerun(ctx, u, uid, entities.Redrive{}, func(job entities.ScheduledJob) error {
+		if job.Status != entities.Success && job.Status != entities.Fail {
+			return fmt.Errorf("%w: cannot replay a %s job", entities.ErrJobNotFinished, job.Status)
+		}
+		return nil
+	})
+}
+
+// rerun schedules a new run of the job in a random partition when check
+// allows it
+func (s *Service) rerun(ctx context.Context, u entities.User, uid string, r entities.Redrive, check func(entities.ScheduledJob) error) (entities.ScheduledJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return entities.ScheduledJob{}, err
+	}
+	defer tx.Rollback()
+	job, err := storage.GetScheduledJobForUpdate(ctx, tx, uid, u.ID)
+	if err != nil {
+		return job, err
+	}
+	if err := check(job); err != nil {
+		return job, err
+	}
+	job = r.Apply(job, time.Now().UTC())
+	job.Partition = s.pick()
+	if err := storage.UpdateRedrivenJob(ctx, tx, job); err != nil {
+		return job, err
+	}
+	if err := storage.Notify(ctx, tx, map[string]int{
+		"partition": job.Partition,
+	}); err != nil {
+		return job, err
+	}
+	return job, tx.Commit()
+}
+
+// RedriveMatching starts a background operation that redrives every failed
+// job matching the filter. Failed jobs whose dedup key is taken by another
+// job are not redriven.
+func (s *Service) RedriveMatching(ctx context.Context, f entities.ScheduledJobFilter, r entities.Redrive) (entities.BulkOperation, error) {
+	now := time.Now().UTC()
+	op := entities.BulkOperation{
+		UID:       uuid.New(),
+		UserID:    f.UserID,
+		Kind:      "redrive",
+		Status:    entities.OperationRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	op, err := storage.InsertBulkOperation(ctx, s.db, op)
+	if err != nil {
+		return op, err
+	}
+	// the batches go forward by id, so a redriven job that fails again
+	// before the operation ends is not redriven twice
+	var lastID int64
+	go s.runBulkOperation(op, func(ctx context.Context) (int, bool, error) {
+		n, last, more, err := s.redriveBatch(ctx, f, r, lastID)
+		lastID = last
+		return n, more, err
+	})
+	return op, nil
+}
+
+// redriveBatch redrives at most bulkBatchSize failed jobs with an id
+// greater than afterID and notifies the affected partitions once each. Only
+// the first of the jobs with the same dedup key is redriven. It returns the
+// id of the last job it selected.
+func (s *Service) redriveBatch(ctx context.Context, f entities.ScheduledJobFilter, r entities.Redrive, afterID int64) (int, int64, bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, afterID, false, err
+	}
+	defer tx.Rollback()
+	jobs, err := storage.SelectFailedJobsForUpdate(ctx, tx, f, afterID, bulkBatchSize)
+	if err != nil {
+		return 0, afterID, false, err
+	}
+	now := time.Now().UTC()
+	dedupKeys := make(map[string]bool)
+	var partitions []int
+	for i := range jobs {
+		if key := jobs[i].DedupKey; len(key) > 0 {
+			if dedupKeys[key] {
+				continue
+			}
+			dedupKeys[key] = true
+		}
+		job := r.Apply(jobs[i], now)
+		job.Partition = s.pick()
+		if err := storage.UpdateRedrivenJob(ctx, tx, job); err != nil {
+			return 0, afterID, false, err
+		}
+		partitions = append(partitions, job.Partition)
+	}
+	if err := notifyPartitions(ctx, tx, partitions); err != nil {
+		return 0, afterID, false, err
+	}
+	if len(jobs) > 0 {
+		afterID = jobs[len(jobs)-1].ID
+	}
+	return len(partitions), afterID, len(jobs) == bulkBatchSize, tx.Commit()
+}
+
+// notifyPartitions notifies each of the given partitions once
+func notifyPartitions(ctx context.Context, db storage.IDB, partitions []int) error {
+	notified := make(map[int]bool)
+	for _, p := range partitions {
+		if notified[p] {
+			continue
+		}
+		notified[p] = true
+		if err := storage.Notify(ctx, db, map[string]int{
+			"partition": p,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOperation returns a bulk operation of the user
+func (s *Service) GetOperation(ctx context.Context, u entities.User, uid string) (entities.BulkOperation, error) {
+	return storage.GetBulkOperation(ctx, s.db, uid, u.ID)
+}
+
+// Cancel moves a scheduled or paused job to Deleted. Jobs that are already
+// executing or finished cannot be cancelled.
+func (s *Service) Cancel(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return entities.ScheduledJob{}, err
+	}
+	defer tx.Rollback()
+	job, err := storage.GetScheduledJobForUpdate(ctx, tx, uid, u.ID)
+	if err != nil {
+		return job, err
+	}
+	switch job.Status {
+	case entities.Scheduled, entities.Paused:
+	case entities.Deleted:
+		return job, tx.Commit()
+	default:
+		return job, fmt.Errorf("%w: cannot cancel a %s job", entities.ErrJobNotScheduled, job.Status)
+	}
+	job.Status = entities.Deleted
+	job.UpdatedAt = time.Now().UTC()
+	if err := storage.UpdateJobStatus(ctx, tx, job); err != nil {
+		return job, err
+	}
+	if err := storage.Notify(ctx, tx, map[string]int{
+		"partition": job.Partition,
+	}); err != nil {
+		return job, err
+	}
+	return job, tx.Commit()
+}
+
+// Update applies changes to a job that is still scheduled or paused and
+// notifies its partition so that an earlier runAt is picked up immediately.
+func (s *Service) Update(ctx context.Context, u entities.User, uid string, apply func(entities.ScheduledJob) (entities.ScheduledJob, error)) (entities.ScheduledJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return entities.ScheduledJob{}, err
+	}
+	defer tx.Rollback()
+	job, err := storage.GetScheduledJobForUpdate(ctx, tx, uid, u.ID)
+	if err != nil {
+		return job, err
+	}
+	if job.Status != entities.Scheduled && job.Status != entities.Paused {
+		return job, fmt.Errorf("%w: cannot edit a %s job", entities.ErrJobNotScheduled, job.Status)
+	}
+	job, err = apply(job)
+	if err != nil {
+		return job, err
+	}
+	job.UpdatedAt = time.Now().UTC()
+	if err := storage.UpdateScheduledJob(ctx, tx, job); err != nil {
+		return job, err
+	}
+	if err := storage.Notify(ctx, tx, map[string]int{
+		"partition": job.Partition,
+	}); err != nil {
+		return job, err
+	}
+	return job, tx.Commit()
+}
+
+// Trigger moves the run_at of a scheduled job to now so that its next run,
+// or the next attempt of its current run, starts immediately. Recurring jobs
+// keep their schedule after it.
+func (s *Service) Trigger(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return entities.ScheduledJob{}, err
+	}
+	defer tx.Rollback()
+	job, err := storage.GetScheduledJobForUpdate(ctx, tx, uid, u.ID)
+	if err != nil {
+		return job, err
+	}
+	if job.Status != entities.Scheduled {
+		return job, fmt.Errorf("%w: cannot trigger a %s job", entities.ErrJobNotScheduled, job.Status)
+	}
+	now := time.Now().UTC()
+	job.RunAt = now
+	job.UpdatedAt = now
+	if err := storage.UpdateJobRunAt(ctx, tx, job); err != nil {
+		return job, err
+	}
+	if err := storage.Notify(ctx, tx, map[string]int{
+		"partition": job.Partition,
+	}); err != nil {
+		return job, err
+	}
+	return job, tx.Commit()
+}
+
+// Pause moves a scheduled job to Paused so that it does not run until it is
+// resumed. Pausing a paused job does nothing.
+func (s *Service) Pause(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return entities.ScheduledJob{}, err
+	}
+	defer tx.Rollback()
+	job, err := storage.GetScheduledJobForUpdate(ctx, tx, uid, u.ID)
+	if err != nil {
+		return job, err
+	}
+	switch job.Status {
+	case entities.Scheduled:
+	case entities.Paused:
+		return job, tx.Commit()
+	default:
+		return job, fmt.Errorf("%w: cannot pause a %s job", entities.ErrJobNotScheduled, job.Status)
+	}
+	job.Status = entities.Paused
+	job.UpdatedAt = time.Now().UTC()
+	if err := storage.UpdateJobStatus(ctx, tx, job); err != nil {
+		return job, err
+	}
+	if err := storage.Notify(ctx, tx, map[string]int{
+		"partition": job.Partition,
+	}); err != nil {
+		return job, err
+	}
+	return job, tx.Commit()
+}
+
+// Resume moves a paused job back to Scheduled. The policy decides what
+// happens when the runAt of the job passed while it was paused.
+// Resuming a scheduled job does nothing.
+func (s *Service) Resume(ctx context.Context, u entities.User, uid string, policy entities.MissedRunPolicy) (entities.ScheduledJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return entities.ScheduledJob{}, err
+	}
+	defer tx.Rollback()
+	job, err := storage.GetScheduledJobForUpdate(ctx, tx, uid, u.ID)
+	if err != nil {
+		return job, err
+	}
+	switch job.Status {
+	case entities.Paused:
+	case entities.Scheduled:
+		return job, tx.Commit()
+	default:
+		return job, fmt.Errorf("%w: cannot resume a %s job", entities.ErrJobNotPaused, job.Status)
+	}
+	job, err = s.resume(ctx, tx, job, policy, time.Now().UTC())
+	if err != nil {
+		return job, err
+	}
+	if job.Status == entities.Scheduled {
+		if err := storage.Notify(ctx, tx, map[string]int{
+			"partition": job.Partition,
+		}); err != nil {
+			return job, err
+		}
+	}
+	return job, tx.Commit()
+}
+
+// PauseAll pauses the account of the user and every scheduled job of it and
+// returns how many jobs were paused. Until the account is resumed new jobs
+// are created paused and pending jobs are paused after they run.
+func (s *Service) PauseAll(ctx context.Context, u entities.User) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if err := storage.SetUserPaused(ctx, tx, u.ID, true); err != nil {
+		return 0, err
+	}
+	partitions, err := storage.PauseUserJobs(ctx, tx, u.ID, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if err := notifyPartitions(ctx, tx, partitions); err != nil {
+		return 0, err
+	}
+	return int64(len(partitions)), tx.Commit()
+}
+
+// ResumeAll resumes the account of the user and every paused job of it in
+// batches and returns how many jobs were resumed.
+func (s *Service) ResumeAll(ctx context.Context, u entities.User, policy entities.MissedRunPolicy) (int64, error) {
+	if err := storage.SetUserPaused(ctx, s.db, u.ID, false); err != nil {
+		return 0, err
+	}
+	var total int64
+	for {
+		n, err := s.resumeBatch(ctx, u, policy)
+		total += int64(n)
+		if err != nil || n < bulkBatchSize {
+			return total, err
+		}
+	}
+}
+
+func (s *Service) resumeBatch(ctx context.Context, u entities.User, policy entities.MissedRunPolicy) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	jobs, err := storage.SelectPausedJobsForUpdate(ctx, tx, u.ID, bulkBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	var partitions []int
+	for i := range jobs {
+		job, err := s.resume(ctx, tx, jobs[i], policy, now)
+		if err != nil {
+			return 0, err
+		}
+		if job.Status == entities.Scheduled {
+			partitions = append(partitions, job.Partition)
+		}
+	}
+	if err := notifyPartitions(ctx, tx, partitions); err != nil {
+		return 0, err
+	}
+	return len(jobs), tx.Commit()
+}
+
+// resume schedules a paused job in a random partition, since the partition
+// it was paused on may be gone, and applies the missed run policy when its
+// runAt has passed.
+func (s *Service) resume(ctx context.Context, db storage.IDB, job entities.ScheduledJob, policy entities.MissedRunPolicy, now time.Time) (entities.ScheduledJob, error) {
+	job.Status = entities.Scheduled
+	job.Partition = s.pick()
+	job.UpdatedAt = now
+	if job.RunAt.Before(now) {
+		switch policy {
+		case entities.MissedRunSkip:
+			job.Attempts, job.RunScheduledAt = 0, time.Time{}
+			if next, ok := schedule.Next(job, now); ok {
+				job.RunAt = next
+			} else {
+				job.Status = entities.Deleted
+			}
+		case entities.MissedRunFail:
+			job.Runs++
+			job.Attempts, job.RunScheduledAt = 0, time.Time{}
+			if next, ok := schedule.Next(job, now); ok {
+				job.RunAt = next
+			} else {
+				job.Status = entities.Fail
+			}
+			execution := entities.Execution{
+				ScheduledJobID: job.ID,
+				Run:            job.Runs,
+				Msg:            "missed while paused",
+				CreatedAt:      now,
+			}
+			if _, err := storage.InsertExecution(ctx, db, execution); err != nil {
+				return job, err
+			}
+		}
+	}
+	return job, storage.UpdateResumedJob(ctx, db, job)
+}
+
 func (s *Service) Get(ctx context.Context, u entities.User, uid string) (entities.ScheduledJob, []entities.Execution, error) {
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -58,3 +850,42 @@ func (s *Service) Get(ctx context.Context, u entities.User, uid string) (entitie
 	}
 	return job, executions, tx.Commit()
 }
+
+// TestDelivery sends the request of the job the way a worker would, signed
+// for the user, and returns it with the outcome without storing anything.
+// The request has an X-HERMESHOOKS-TEST header so receivers can tell it
+// apart. Every user can send TestDeliveryRate of them per minute.
+func (s *Service) TestDelivery(ctx context.Context, u entities.User, job entities.ScheduledJob) (entities.TestDelivery, error) {
+	now := time.Now().UTC()
+	if !s.testLimiter.allow(u.ID, now) {
+		return entities.TestDelivery{}, entities.ErrRateLimited
+	}
+	ctx, cancel := context.WithTimeout(ctx, testDeliveryTimeout)
+	defer cancel()
+	job.RunScheduledAt = now
+	req, err := delivery.NewRequest(ctx, job)
+	if err != nil {
+		return entities.TestDelivery{}, err
+	}
+	req.Header.Set("X-HERMESHOOKS-TEST", "true")
+	if err := s.signer.Sign(req, u, []byte(job.Payload), now); err != nil {
+		return entities.TestDelivery{}, err
+	}
+	recorder := delivery.Recorder{
+		Client:    s.testClient,
+		BodyLimit: testDeliveryBodyLimit,
+		Criteria:  job.SuccessCriteria,
+		Attempt:   entities.Execution{Attempt: 1},
+	}
+	if _, err := recorder.Send(req); err != nil {
+		s.log.Debug().Err(err).Str("url", job.Url).Msg("test delivery failed")
+	}
+	ans := entities.TestDelivery{
+		Method:    req.Method,
+		Url:       req.URL.String(),
+		Headers:   req.Header,
+		Body:      job.Payload,
+		Execution: recorder.Attempt,
+	}
+	return ans, nil
+}
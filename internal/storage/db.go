@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/uptrace/bun"
@@ -160,6 +163,9 @@ func InsertScheduledJob(ctx context.Context, db IDB, job entities.ScheduledJob)
 	j := FromScheduledJobEntity(job)
 	_, err := db.NewInsert().Model(&j).ExcludeColumn("id").Returning("id").Exec(ctx)
 	if err != nil {
+		if isUniqueViolation(err) && len(job.DedupKey) > 0 {
+			return job, entities.ErrDuplicateJob
+		}
 		return job, err
 	}
 	if err := Notify(ctx, db, map[string]int{
@@ -171,6 +177,33 @@ func InsertScheduledJob(ctx context.Context, db IDB, job entities.ScheduledJob)
 	return job, err
 }
 
+// InsertScheduledJobs inserts the jobs with a single statement. Unlike
+// InsertScheduledJob it does not notify the partitions.
+func InsertScheduledJobs(ctx context.Context, db IDB, jobs []entities.ScheduledJob) ([]entities.ScheduledJob, error) {
+	if len(jobs) == 0 {
+		return jobs, nil
+	}
+	items := make([]ScheduledJob, len(jobs), len(jobs))
+	for i := range jobs {
+		items[i] = FromScheduledJobEntity(jobs[i])
+	}
+	if _, err := db.NewInsert().
+		Model(&items).
+		ExcludeColumn("id").
+		Returning("id").
+		Exec(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return jobs, entities.ErrDuplicateJob
+		}
+		return jobs, err
+	}
+	ans := make([]entities.ScheduledJob, len(items), len(items))
+	for i := range items {
+		ans[i] = ToScheduledJobEntity(items[i])
+	}
+	return ans, nil
+}
+
 func GetScheduledJob(ctx context.Context, db IDB, id string, userId int64) (entities.ScheduledJob, error) {
 	var j ScheduledJob
 	if err := db.NewSelect().
@@ -178,11 +211,186 @@ func GetScheduledJob(ctx context.Context, db IDB, id string, userId int64) (enti
 		Where("uid = ?", id).
 		Where("user_id = ?", userId).
 		Scan(ctx); err != nil {
-		return entities.ScheduledJob{}, err
+		return entities.ScheduledJob{}, notFound(err)
 	}
 	return ToScheduledJobEntity(j), nil
 }
 
+// ListScheduledJobs returns up to limit jobs matching the filter ordered by
+// (run_at, id). Pagination is keyset based: only jobs after the cursor are
+// returned, or before it when desc is set.
+func ListScheduledJobs(ctx context.Context, db IDB, f entities.ScheduledJobFilter, after entities.ScheduledJobCursor, limit int, desc bool) ([]entities.ScheduledJob, error) {
+	var items []ScheduledJob
+	q := db.NewSelect().Model(&items)
+	q = filterScheduledJobs(q, f)
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	if after.ID > 0 {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		q = q.Where("(run_at, id) "+op+" (?, ?)", after.RunAt, after.ID)
+	}
+	if err := q.
+		OrderExpr("run_at " + order).
+		OrderExpr("id " + order).
+		Limit(limit).
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	ans := make([]entities.ScheduledJob, len(items), len(items))
+	for i := range items {
+		ans[i] = ToScheduledJobEntity(items[i])
+	}
+	return ans, nil
+}
+
+func filterScheduledJobs(q *bun.SelectQuery, f entities.ScheduledJobFilter) *bun.SelectQuery {
+	q = q.Where("user_id = ?", f.UserID)
+	if len(f.Statuses) > 0 {
+		q = q.Where("status IN (?)", bun.In(f.Statuses))
+	}
+	if len(f.NamePrefix) > 0 {
+		q = q.Where("name LIKE ?", escapeLike(f.NamePrefix)+"%")
+	}
+	if len(f.UrlHost) > 0 {
+		q = q.Where("lower(substring(url from ?)) = lower(?)", urlHostPattern, f.UrlHost)
+	}
+	if len(f.Tags) > 0 {
+		q = q.Where("tags @> ?", pgdialect.Array(f.Tags))
+	}
+	if !f.RunAtFrom.IsZero() {
+		q = q.Where("run_at >= ?", f.RunAtFrom)
+	}
+	if !f.RunAtTo.IsZero() {
+		q = q.Where("run_at < ?", f.RunAtTo)
+	}
+	if !f.CreatedFrom.IsZero() {
+		q = q.Where("created_at >= ?", f.CreatedFrom)
+	}
+	if !f.CreatedTo.IsZero() {
+		q = q.Where("created_at < ?", f.CreatedTo)
+	}
+	return q
+}
+
+// urlHostPattern extracts the host of a url, skipping any userinfo and port
+const urlHostPattern = `^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/]*@)?([^/:?#]+)`
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// GetScheduledJobForUpdate locks the job row until the transaction ends.
+// This serializes changes with SelectJobsForExecution which locks the rows
+// it moves to Pending.
+func GetScheduledJobForUpdate(ctx context.Context, db IDB, id string, userId int64) (entities.ScheduledJob, error) {
+	var j ScheduledJob
+	if err := db.NewSelect().
+		Model(&j).
+		Where("uid = ?", id).
+		Where("user_id = ?", userId).
+		For("update").
+		Scan(ctx); err != nil {
+		return entities.ScheduledJob{}, notFound(err)
+	}
+	return ToScheduledJobEntity(j), nil
+}
+
+// GetJobByDedupKeyForUpdate locks the scheduled, paused or pending job of the
+// user with the given dedup key.
+func GetJobByDedupKeyForUpdate(ctx context.Context, db IDB, userId int64, key string) (entities.ScheduledJob, error) {
+	var j ScheduledJob
+	if err := db.NewSelect().
+		Model(&j).
+		Where("user_id = ?", userId).
+		Where("dedup_key = ?", key).
+		Where("status IN (?)", bun.In([]entities.ScheduledJobStatus{
+			entities.Scheduled, entities.Paused, entities.Pending,
+		})).
+		For("update").
+		Scan(ctx); err != nil {
+		return entities.ScheduledJob{}, notFound(err)
+	}
+	return ToScheduledJobEntity(j), nil
+}
+
+// SupersedeJob releases the dedup key of a pending job that a new job with
+// the same key replaces and marks it so that it does not run again once the
+// running attempt ends.
+func SupersedeJob(ctx context.Context, db IDB, id int64, now time.Time) error {
+	_, err := db.NewUpdate().
+		Model((*ScheduledJob)(nil)).
+		Set("dedup_key = NULL").
+		Set("superseded = true").
+		Set("updated_at = ?", now).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// IsJobSupersededForUpdate locks the job and reports whether a job with the
+// same dedup key replaced it
+func IsJobSupersededForUpdate(ctx context.Context, db IDB, id int64) (bool, error) {
+	var superseded bool
+	if err := db.NewSelect().
+		Model((*ScheduledJob)(nil)).
+		Column("superseded").
+		Where("id = ?", id).
+		For("update").
+		Scan(ctx, &superseded); err != nil {
+		return false, notFound(err)
+	}
+	return superseded, nil
+}
+
+func isUniqueViolation(err error) bool {
+	const code = "23505"
+	var pgxErr *pgconn.PgError
+	if errors.As(err, &pgxErr) {
+		return pgxErr.Code == code
+	}
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		return pgErr.Field('C') == code
+	}
+	return false
+}
+
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.ErrJobNotFound
+	}
+	return err
+}
+
+// UpdateScheduledJob stores the user editable fields of a job
+func UpdateScheduledJob(ctx context.Context, db IDB, job entities.ScheduledJob) error {
+	j := FromScheduledJobEntity(job)
+	_, err := db.NewUpdate().
+		Model(&j).
+		Column("name").
+		Column("description").
+		Column("url").
+		Column("payload").
+		Column("content_type").
+		Column("run_at").
+		Column("retries").
+		Column("method").
+		Column("request_options").
+		Column("success_criteria").
+		Column("retry_policy").
+		Column("attempts").
+		Column("run_scheduled_at").
+		Column("updated_at").
+		Where("id = ?", j.ID).
+		Exec(ctx)
+	return err
+}
+
 func UpdateScheduledJobsPartitions(ctx context.Context, db IDB, job entities.ScheduledJob) error {
 	j := FromScheduledJobEntity(job)
 	_, err := db.NewUpdate().Model(&j).Column("partition").
@@ -326,6 +534,18 @@ func ReBalance(ctx context.Context, db IDB, active []int) error {
 	return nil
 }
 
+// UpdateJobRunAt stores the run_at of a job
+func UpdateJobRunAt(ctx context.Context, db IDB, job entities.ScheduledJob) error {
+	j := FromScheduledJobEntity(job)
+	_, err := db.NewUpdate().
+		Model(&j).
+		Column("run_at").
+		Column("updated_at").
+		Where("id = ?", j.ID).
+		Exec(ctx)
+	return err
+}
+
 func UpdateJobStatus(ctx context.Context, db IDB, job entities.ScheduledJob) error {
 	j := FromScheduledJobEntity(job)
 	_, err := db.NewUpdate().
@@ -337,12 +557,37 @@ func UpdateJobStatus(ctx context.Context, db IDB, job entities.ScheduledJob) err
 	return err
 }
 
+// UpdateJobAfterRun stores the outcome of a job execution. Jobs that go
+// back to Scheduled, for their next run or for a retry, notify their
+// partition so the monitor picks up the new run_at.
+func UpdateJobAfterRun(ctx context.Context, db IDB, job entities.ScheduledJob) error {
+	j := FromScheduledJobEntity(job)
+	if _, err := db.NewUpdate().
+		Model(&j).
+		Column("status").
+		Column("run_at").
+		Column("runs").
+		Column("attempts").
+		Column("run_scheduled_at").
+		Column("updated_at").
+		Where("id = ?", j.ID).
+		Exec(ctx); err != nil {
+		return err
+	}
+	if job.Status != entities.Scheduled {
+		return nil
+	}
+	return Notify(ctx, db, map[string]int{
+		"partition": job.Partition,
+	})
+}
+
 func SelectExecutions(ctx context.Context, db IDB, jobID int64) ([]entities.Execution, error) {
 	var items []Execution
 	if err := db.NewSelect().
 		Model(&items).
 		Where("scheduled_job_id = ?", jobID).
-		Order("created_at desc").
+		Order("created_at desc", "id desc").
 		Scan(ctx); err != nil {
 		return nil, err
 	}
@@ -353,6 +598,29 @@ func SelectExecutions(ctx context.Context, db IDB, jobID int64) ([]entities.Exec
 	return ans, nil
 }
 
+// SelectLastRunExecutions returns the executions of the last run of each
+// job by job id, the latest first
+func SelectLastRunExecutions(ctx context.Context, db IDB, jobIDs []int64) (map[int64][]entities.Execution, error) {
+	ans := make(map[int64][]entities.Execution)
+	if len(jobIDs) == 0 {
+		return ans, nil
+	}
+	var items []Execution
+	if err := db.NewSelect().
+		Model(&items).
+		Join("JOIN scheduled_jobs AS j ON j.id = execution.scheduled_job_id AND j.runs = execution.run").
+		Where("execution.scheduled_job_id IN (?)", bun.In(jobIDs)).
+		Order("execution.created_at desc", "execution.id desc").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	for i := range items {
+		e := ToEntitiesExecution(items[i])
+		ans[e.ScheduledJobID] = append(ans[e.ScheduledJobID], e)
+	}
+	return ans, nil
+}
+
 func InsertExecution(ctx context.Context, db IDB, job entities.Execution) (entities.Execution, error) {
 	e := FromEntitiesExecution(job)
 	if _, err := db.NewInsert().
@@ -367,6 +635,38 @@ func InsertExecution(ctx context.Context, db IDB, job entities.Execution) (entit
 
 }
 
+// InsertExecutions inserts the attempts of a run
+func InsertExecutions(ctx context.Context, db IDB, executions []entities.Execution) error {
+	if len(executions) == 0 {
+		return nil
+	}
+	items := make([]Execution, len(executions))
+	for i := range executions {
+		items[i] = FromEntitiesExecution(executions[i])
+	}
+	_, err := db.NewInsert().
+		Model(&items).
+		ExcludeColumn("id").
+		Exec(ctx)
+	return err
+}
+
+// ClearExecutionResponses removes the response headers and bodies of the
+// executions created before the given time
+func ClearExecutionResponses(ctx context.Context, db IDB, before time.Time) (int64, error) {
+	res, err := db.NewUpdate().
+		Model((*Execution)(nil)).
+		Set("response_headers = NULL").
+		Set("response_body = ''").
+		Where("response_headers IS NOT NULL").
+		Where("created_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func InsertUser(ctx context.Context, db IDB, u entities.User) (entities.User, error) {
 	su := FromEntitiesUser(u)
 	if _, err := db.NewInsert().
@@ -409,3 +709,285 @@ func GetUserByUserName(ctx context.Context, db IDB, username string) (entities.U
 	}
 	return ToEntitiesUser(u), nil
 }
+
+func GetUserByID(ctx context.Context, db IDB, id int64) (entities.User, error) {
+	var u User
+	if err := db.NewSelect().
+		Model(&u).
+		Where("id = ?", id).
+		Scan(ctx); err != nil {
+		return entities.User{}, err
+	}
+	return ToEntitiesUser(u), nil
+}
+
+// UpdateUserSigningSecrets stores the current and previous signing secrets
+func UpdateUserSigningSecrets(ctx context.Context, db IDB, u entities.User) error {
+	su := FromEntitiesUser(u)
+	_, err := db.NewUpdate().
+		Model(&su).
+		Column("signing_secret").
+		Column("previous_signing_secret").
+		Column("previous_signing_secret_expires_at").
+		Where("id = ?", su.ID).
+		Exec(ctx)
+	return err
+}
+
+// CancelScheduledJobs moves up to limit scheduled or paused jobs that match
+// the filter to Deleted. It returns the partitions of the cancelled jobs, one
+// entry per job, so an empty result means nothing matched anymore.
+func CancelScheduledJobs(ctx context.Context, db IDB, f entities.ScheduledJobFilter, limit int, now time.Time) ([]int, error) {
+	f.Statuses = []entities.ScheduledJobStatus{entities.Scheduled, entities.Paused}
+	sub := filterScheduledJobs(
+		db.NewSelect().Model((*ScheduledJob)(nil)).Column("id"),
+		f,
+	).Limit(limit).For("update")
+	var partitions []int
+	if _, err := db.NewUpdate().
+		Model((*ScheduledJob)(nil)).
+		Set("status = ?", entities.Deleted).
+		Set("updated_at = ?", now).
+		Where("id IN (?)", sub).
+		Returning("partition").
+		Exec(ctx, &partitions); err != nil {
+		return nil, err
+	}
+	return partitions, nil
+}
+
+// PauseUserJobs moves every scheduled job of the user to Paused and returns
+// the partitions of the paused jobs, one entry per job.
+func PauseUserJobs(ctx context.Context, db IDB, userId int64, now time.Time) ([]int, error) {
+	var partitions []int
+	if _, err := db.NewUpdate().
+		Model((*ScheduledJob)(nil)).
+		Set("status = ?", entities.Paused).
+		Set("updated_at = ?", now).
+		Where("user_id = ?", userId).
+		Where("status = ?", entities.Scheduled).
+		Returning("partition").
+		Exec(ctx, &partitions); err != nil {
+		return nil, err
+	}
+	return partitions, nil
+}
+
+// SetUserPaused stores whether the jobs of the user are paused
+func SetUserPaused(ctx context.Context, db IDB, userId int64, paused bool) error {
+	_, err := db.NewUpdate().
+		Model((*User)(nil)).
+		Set("paused = ?", paused).
+		Where("id = ?", userId).
+		Exec(ctx)
+	return err
+}
+
+// IsUserPausedForShare reports whether the jobs of the user are paused. The
+// user is locked so that the account cannot be paused or resumed before
+// the caller commits.
+func IsUserPausedForShare(ctx context.Context, db IDB, userId int64) (bool, error) {
+	var paused bool
+	if err := db.NewSelect().
+		Model((*User)(nil)).
+		Column("paused").
+		Where("id = ?", userId).
+		For("share").
+		Scan(ctx, &paused); err != nil {
+		return false, notFound(err)
+	}
+	return paused, nil
+}
+
+// SelectPausedJobsForUpdate locks up to limit paused jobs of the user
+func SelectPausedJobsForUpdate(ctx context.Context, db IDB, userId int64, limit int) ([]entities.ScheduledJob, error) {
+	var items []ScheduledJob
+	if err := db.NewSelect().
+		Model(&items).
+		Where("user_id = ?", userId).
+		Where("status = ?", entities.Paused).
+		OrderExpr("id").
+		Limit(limit).
+		For("update").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	ans := make([]entities.ScheduledJob, len(items), len(items))
+	for i := range items {
+		ans[i] = ToScheduledJobEntity(items[i])
+	}
+	return ans, nil
+}
+
+// UpdateResumedJob stores a job that left the paused status. The partition
+// is stored too since the job may have been paused on a partition that no
+// longer exists.
+func UpdateResumedJob(ctx context.Context, db IDB, job entities.ScheduledJob) error {
+	j := FromScheduledJobEntity(job)
+	_, err := db.NewUpdate().
+		Model(&j).
+		Column("status").
+		Column("run_at").
+		Column("runs").
+		Column("attempts").
+		Column("run_scheduled_at").
+		Column("partition").
+		Column("updated_at").
+		Where("id = ?", j.ID).
+		Exec(ctx)
+	return err
+}
+
+// SelectFailedJobsForUpdate locks up to limit failed jobs matching the
+// filter with an id greater than afterID. Jobs whose dedup key is taken by
+// another scheduled, pending or paused job are left out since they cannot
+// be scheduled again.
+func SelectFailedJobsForUpdate(ctx context.Context, db IDB, f entities.ScheduledJobFilter, afterID int64, limit int) ([]entities.ScheduledJob, error) {
+	f.Statuses = []entities.ScheduledJobStatus{entities.Fail}
+	var items []ScheduledJob
+	q := filterScheduledJobs(db.NewSelect().Model(&items), f)
+	if err := q.
+		Where(`NOT EXISTS (SELECT 1 FROM scheduled_jobs AS o WHERE o.user_id = scheduled_job.user_id
+			AND o.dedup_key = scheduled_job.dedup_key AND o.status IN (?))`,
+			bun.In([]entities.ScheduledJobStatus{entities.Scheduled, entities.Pending, entities.Paused})).
+		Where("scheduled_job.id > ?", afterID).
+		OrderExpr("scheduled_job.id").
+		Limit(limit).
+		For("update").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	ans := make([]entities.ScheduledJob, len(items), len(items))
+	for i := range items {
+		ans[i] = ToScheduledJobEntity(items[i])
+	}
+	return ans, nil
+}
+
+// UpdateRedrivenJob stores a finished job that was scheduled again. It returns
+// ErrDuplicateJob when its dedup key is taken by another job.
+func UpdateRedrivenJob(ctx context.Context, db IDB, job entities.ScheduledJob) error {
+	j := FromScheduledJobEntity(job)
+	_, err := db.NewUpdate().
+		Model(&j).
+		Column("status").
+		Column("url").
+		Column("run_at").
+		Column("attempts").
+		Column("run_scheduled_at").
+		Column("partition").
+		Column("updated_at").
+		Where("id = ?", j.ID).
+		Exec(ctx)
+	if isUniqueViolation(err) {
+		return entities.ErrDuplicateJob
+	}
+	return err
+}
+
+// InsertBulkOperation stores a new operation. It returns
+// ErrOperationRunning when the user has another running operation.
+func InsertBulkOperation(ctx context.Context, db IDB, o entities.BulkOperation) (entities.BulkOperation, error) {
+	m := FromEntitiesBulkOperation(o)
+	if _, err := db.NewInsert().
+		Model(&m).
+		ExcludeColumn("id").
+		Returning("id").
+		Exec(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return o, entities.ErrOperationRunning
+		}
+		return o, err
+	}
+	return ToEntitiesBulkOperation(m), nil
+}
+
+func UpdateBulkOperation(ctx context.Context, db IDB, o entities.BulkOperation) error {
+	m := FromEntitiesBulkOperation(o)
+	_, err := db.NewUpdate().
+		Model(&m).
+		Column("status", "affected", "error", "updated_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// FailInterruptedBulkOperations marks the operations that are still running
+// but were created before the given time as failed
+func FailInterruptedBulkOperations(ctx context.Context, db IDB, before time.Time) (int64, error) {
+	res, err := db.NewUpdate().
+		Model((*BulkOperation)(nil)).
+		Set("status = ?", entities.OperationFailed).
+		Set("error = ?", "interrupted").
+		Set("updated_at = ?", time.Now().UTC()).
+		Where("status = ?", entities.OperationRunning).
+		Where("created_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func GetBulkOperation(ctx context.Context, db IDB, id string, userId int64) (entities.BulkOperation, error) {
+	var m BulkOperation
+	if err := db.NewSelect().
+		Model(&m).
+		Where("uid = ?", id).
+		Where("user_id = ?", userId).
+		Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.BulkOperation{}, entities.ErrOperationNotFound
+		}
+		return entities.BulkOperation{}, err
+	}
+	return ToEntitiesBulkOperation(m), nil
+}
+
+// InsertIdempotencyKey stores the key unless the user has already used it.
+// The boolean reports whether the key was stored. A concurrent insert of the
+// same key blocks until the other transaction finishes.
+func InsertIdempotencyKey(ctx context.Context, db IDB, k entities.IdempotencyKey) (bool, error) {
+	m := FromEntitiesIdempotencyKey(k)
+	res, err := db.NewInsert().
+		Model(&m).
+		ExcludeColumn("id").
+		On("CONFLICT (user_id, key) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func GetIdempotencyKey(ctx context.Context, db IDB, userID int64, key string) (entities.IdempotencyKey, error) {
+	var m IdempotencyKey
+	if err := db.NewSelect().
+		Model(&m).
+		Where("user_id = ?", userID).
+		Where("key = ?", key).
+		Scan(ctx); err != nil {
+		return entities.IdempotencyKey{}, err
+	}
+	return ToEntitiesIdempotencyKey(m), nil
+}
+
+// DeleteIdempotencyKeys deletes the keys created before the given time.
+// When key is not empty only that key of the user is deleted.
+func DeleteIdempotencyKeys(ctx context.Context, db IDB, before time.Time, userID int64, key string) (int64, error) {
+	q := db.NewDelete().
+		Model((*IdempotencyKey)(nil)).
+		Where("created_at < ?", before)
+	if len(key) > 0 {
+		q = q.Where("user_id = ?", userID).Where("key = ?", key)
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
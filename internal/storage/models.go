@@ -12,21 +12,121 @@ import (
 type ScheduledJob struct {
 	bun.BaseModel
 
-	ID          int64 `bun:"id,pk,autoincrement"`
-	UID         uuid.UUID
-	UserID      int64
-	Name        string
-	Description string
-	Url         string
-	Payload     string
-	ContentType string
-	Signature   string
-	RunAt       time.Time
-	Retries     int
-	Status      int
-	Partition   int
-	CreatedAt   time.Time
-	UpdatedAt   bun.NullTime
+	ID              int64 `bun:"id,pk,autoincrement"`
+	UID             uuid.UUID
+	UserID          int64
+	Name            string
+	Description     string
+	Url             string
+	Payload         string
+	ContentType     string
+	Signature       string
+	RunAt           time.Time
+	Retries         int
+	Method          string
+	RequestOptions  RequestOptions  `bun:"type:jsonb"`
+	SuccessCriteria SuccessCriteria `bun:"type:jsonb"`
+	RetryPolicy     RetryPolicy     `bun:"type:jsonb"`
+	Attempts        int
+	RunScheduledAt  bun.NullTime
+	Schedule        string
+	Timezone        string
+	DstPolicy       int
+	IntervalSeconds int64
+	StartAt         bun.NullTime
+	EndAt           bun.NullTime
+	MaxRuns         int
+	Runs            int
+	Tags            []string `bun:",array"`
+	DedupKey        string   `bun:",nullzero"`
+	DedupPolicy     int
+	Status          int
+	Partition       int
+	CreatedAt       time.Time
+	UpdatedAt       bun.NullTime
+}
+
+// RequestOptions holds the custom headers and query parameters of a job
+type RequestOptions struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
+}
+
+// SuccessCriteria is the stored form of entities.SuccessCriteria
+type SuccessCriteria struct {
+	SuccessCodes   []string        `json:"successCodes,omitempty"`
+	RetryableCodes []string        `json:"retryableCodes,omitempty"`
+	Assertions     []BodyAssertion `json:"assertions,omitempty"`
+}
+
+type BodyAssertion struct {
+	JSONPath string `json:"jsonPath,omitempty"`
+	Equals   string `json:"equals,omitempty"`
+	Contains string `json:"contains,omitempty"`
+}
+
+func FromSuccessCriteriaEntity(c entities.SuccessCriteria) SuccessCriteria {
+	var ans SuccessCriteria
+	for _, r := range c.SuccessCodes {
+		ans.SuccessCodes = append(ans.SuccessCodes, r.String())
+	}
+	for _, r := range c.RetryableCodes {
+		ans.RetryableCodes = append(ans.RetryableCodes, r.String())
+	}
+	for _, a := range c.Assertions {
+		ans.Assertions = append(ans.Assertions, BodyAssertion(a))
+	}
+	return ans
+}
+
+func ToSuccessCriteriaEntity(c SuccessCriteria) entities.SuccessCriteria {
+	var ans entities.SuccessCriteria
+	for _, s := range c.SuccessCodes {
+		if r, err := entities.ParseStatusRange(s); err == nil {
+			ans.SuccessCodes = append(ans.SuccessCodes, r)
+		}
+	}
+	for _, s := range c.RetryableCodes {
+		if r, err := entities.ParseStatusRange(s); err == nil {
+			ans.RetryableCodes = append(ans.RetryableCodes, r)
+		}
+	}
+	for _, a := range c.Assertions {
+		ans.Assertions = append(ans.Assertions, entities.BodyAssertion(a))
+	}
+	return ans
+}
+
+// RetryPolicy is the stored form of entities.RetryPolicy
+type RetryPolicy struct {
+	MaxAttempts    int     `json:"maxAttempts,omitempty"`
+	InitialDelayMs int64   `json:"initialDelayMs,omitempty"`
+	Multiplier     float64 `json:"multiplier,omitempty"`
+	MaxDelayMs     int64   `json:"maxDelayMs,omitempty"`
+	Jitter         float64 `json:"jitter,omitempty"`
+	MaxAgeMs       int64   `json:"maxAgeMs,omitempty"`
+}
+
+func FromRetryPolicyEntity(p entities.RetryPolicy) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    p.MaxAttempts,
+		InitialDelayMs: p.InitialDelay.Milliseconds(),
+		Multiplier:     p.Multiplier,
+		MaxDelayMs:     p.MaxDelay.Milliseconds(),
+		Jitter:         p.Jitter,
+		MaxAgeMs:       p.MaxAge.Milliseconds(),
+	}
+}
+
+func ToRetryPolicyEntity(p RetryPolicy) entities.RetryPolicy {
+	return entities.RetryPolicy{
+		MaxAttempts:  p.MaxAttempts,
+		InitialDelay: time.Duration(p.InitialDelayMs) * time.Millisecond,
+		Multiplier:   p.Multiplier,
+		MaxDelay:     time.Duration(p.MaxDelayMs) * time.Millisecond,
+		Jitter:       p.Jitter,
+		MaxAge:       time.Duration(p.MaxAgeMs) * time.Millisecond,
+	}
 }
 
 func FromScheduledJobEntity(j entities.ScheduledJob) ScheduledJob {
@@ -42,10 +142,33 @@ func FromScheduledJobEntity(j entities.ScheduledJob) ScheduledJob {
 		Signature:   j.Signature,
 		RunAt:       j.RunAt,
 		Retries:     j.Retries,
-		Status:      int(j.Status),
-		Partition:   j.Partition,
-		CreatedAt:   j.CreatedAt,
-		UpdatedAt:   bun.NullTime{Time: j.UpdatedAt},
+		Method:      j.Method,
+		RequestOptions: RequestOptions{
+			Headers: j.Headers,
+			Query:   j.Query,
+		},
+		SuccessCriteria: FromSuccessCriteriaEntity(j.SuccessCriteria),
+		RetryPolicy:     FromRetryPolicyEntity(j.RetryPolicy),
+		Attempts:        j.Attempts,
+		RunScheduledAt:  bun.NullTime{Time: j.RunScheduledAt},
+		Schedule:        j.Schedule,
+		Timezone:        j.Timezone,
+		DstPolicy:       int(j.DSTPolicy),
+		IntervalSeconds: int64(j.Interval / time.Second),
+		StartAt:         bun.NullTime{Time: j.StartAt},
+		EndAt:           bun.NullTime{Time: j.EndAt},
+		MaxRuns:         j.MaxRuns,
+		Runs:            j.Runs,
+		Tags:            j.Tags,
+		DedupKey:        j.DedupKey,
+		DedupPolicy:     int(j.DedupPolicy),
+		Status:          int(j.Status),
+		Partition:       j.Partition,
+		CreatedAt:       j.CreatedAt,
+		UpdatedAt:       bun.NullTime{Time: j.UpdatedAt},
+	}
+	if ans.Tags == nil {
+		ans.Tags = []string{}
 	}
 	return ans
 }
@@ -63,60 +186,118 @@ func ToScheduledJobEntity(j ScheduledJob) entities.ScheduledJob {
 		Signature:   j.Signature,
 		RunAt:       j.RunAt,
 		Retries:     j.Retries,
+		Method:      j.Method,
+		Headers:     j.RequestOptions.Headers,
+		Query:       j.RequestOptions.Query,
+		Schedule:    j.Schedule,
+		Timezone:    j.Timezone,
+		DSTPolicy:   entities.DSTPolicy(j.DstPolicy),
+		Interval:    time.Duration(j.IntervalSeconds) * time.Second,
+		StartAt:     j.StartAt.Time,
+		EndAt:       j.EndAt.Time,
+		MaxRuns:     j.MaxRuns,
+		Runs:        j.Runs,
+		Tags:        j.Tags,
+		DedupKey:    j.DedupKey,
+		DedupPolicy: entities.DedupPolicy(j.DedupPolicy),
 		Status:      entities.ScheduledJobStatus(j.Status),
 		Partition:   j.Partition,
 		CreatedAt:   j.CreatedAt,
 		UpdatedAt:   j.UpdatedAt.Time,
 	}
+	ans.SuccessCriteria = ToSuccessCriteriaEntity(j.SuccessCriteria)
+	ans.RetryPolicy = ToRetryPolicyEntity(j.RetryPolicy)
+	ans.Attempts = j.Attempts
+	ans.RunScheduledAt = j.RunScheduledAt.Time
 	return ans
 }
 
 type Execution struct {
 	bun.BaseModel
 
-	ID             int64 `bun:"id,pk,autoincrement"`
-	ScheduledJobID int64 `bun:"scheduled_job_id"`
-	StatusCode     int
-	Msg            string
-	CreatedAt      time.Time
+	ID                int64 `bun:"id,pk,autoincrement"`
+	ScheduledJobID    int64 `bun:"scheduled_job_id"`
+	Run               int
+	Attempt           int
+	StatusCode        int
+	Msg               string
+	ErrorClass        int
+	StartedAt         bun.NullTime
+	FinishedAt        bun.NullTime
+	Worker            string
+	Partition         int
+	ResponseHeaders   map[string][]string `bun:"type:jsonb,nullzero"`
+	ResponseBody      string
+	ResponseTruncated bool
+	CreatedAt         time.Time
+
+	RetryAfter bun.NullTime
 }
 
 func FromEntitiesExecution(e entities.Execution) Execution {
 	ans := Execution{
-		ID:             e.ID,
-		ScheduledJobID: e.ScheduledJobID,
-		StatusCode:     e.StatusCode,
-		Msg:            e.Msg,
-		CreatedAt:      e.CreatedAt,
+		ID:                e.ID,
+		ScheduledJobID:    e.ScheduledJobID,
+		Run:               e.Run,
+		Attempt:           e.Attempt,
+		StatusCode:        e.StatusCode,
+		Msg:               e.Msg,
+		ErrorClass:        int(e.ErrorClass),
+		StartedAt:         bun.NullTime{Time: e.StartedAt},
+		FinishedAt:        bun.NullTime{Time: e.FinishedAt},
+		Worker:            e.Worker,
+		Partition:         e.Partition,
+		ResponseHeaders:   e.ResponseHeaders,
+		ResponseBody:      e.ResponseBody,
+		ResponseTruncated: e.ResponseTruncated,
+		CreatedAt:         e.CreatedAt,
 	}
+	ans.RetryAfter = bun.NullTime{Time: e.RetryAfter}
 	return ans
 }
 
 func ToEntitiesExecution(e Execution) entities.Execution {
 	ans := entities.Execution{
-		ID:             e.ID,
-		ScheduledJobID: e.ScheduledJobID,
-		StatusCode:     e.StatusCode,
-		Msg:            e.Msg,
-		CreatedAt:      e.CreatedAt,
+		ID:                e.ID,
+		ScheduledJobID:    e.ScheduledJobID,
+		Run:               e.Run,
+		Attempt:           e.Attempt,
+		StatusCode:        e.StatusCode,
+		Msg:               e.Msg,
+		ErrorClass:        entities.ErrorClass(e.ErrorClass),
+		StartedAt:         e.StartedAt.Time,
+		FinishedAt:        e.FinishedAt.Time,
+		Worker:            e.Worker,
+		Partition:         e.Partition,
+		ResponseHeaders:   e.ResponseHeaders,
+		ResponseBody:      e.ResponseBody,
+		ResponseTruncated: e.ResponseTruncated,
+		CreatedAt:         e.CreatedAt,
 	}
+	ans.RetryAfter = e.RetryAfter.Time
 	return ans
 }
 
 type User struct {
 	bun.BaseModel
 
-	ID        int64
-	Username  string
-	ApiKey    *string
-	CreatedAt time.Time
+	ID                             int64
+	Username                       string
+	ApiKey                         *string
+	CreatedAt                      time.Time
+	SigningSecret                  string
+	PreviousSigningSecret          string
+	PreviousSigningSecretExpiresAt bun.NullTime
 }
 
 func FromEntitiesUser(u entities.User) User {
 	ans := User{
-		ID:        u.ID,
-		Username:  u.Username,
-		CreatedAt: u.CreatedAt,
+		ID:                             u.ID,
+		Username:                       u.Username,
+		CreatedAt:                      u.CreatedAt,
+		SigningSecret:                  u.SigningSecret,
+		PreviousSigningSecret:          u.PreviousSigningSecret,
+		PreviousSigningSecretExpiresAt: bun.NullTime{Time: u.PreviousSigningSecretExpiresAt},
 	}
 	if len(u.ApiKey) > 0 {
 		apiKey := cryptoutils.Sha256(u.ApiKey)
@@ -127,9 +308,94 @@ func FromEntitiesUser(u entities.User) User {
 
 func ToEntitiesUser(u User) entities.User {
 	ans := entities.User{
-		ID:        u.ID,
-		Username:  u.Username,
-		CreatedAt: u.CreatedAt,
+		ID:                             u.ID,
+		Username:                       u.Username,
+		CreatedAt:                      u.CreatedAt,
+		SigningSecret:                  u.SigningSecret,
+		PreviousSigningSecret:          u.PreviousSigningSecret,
+		PreviousSigningSecretExpiresAt: u.PreviousSigningSecretExpiresAt.Time,
+	}
+	return ans
+}
+
+type IdempotencyKey struct {
+	bun.BaseModel
+
+	ID          int64 `bun:"id,pk,autoincrement"`
+	UserID      int64
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Response    string
+	CreatedAt   time.Time
+}
+
+func FromEntitiesIdempotencyKey(k entities.IdempotencyKey) IdempotencyKey {
+	ans := IdempotencyKey{
+		ID:          k.ID,
+		UserID:      k.UserID,
+		Key:         k.Key,
+		RequestHash: k.RequestHash,
+		StatusCode:  k.StatusCode,
+		Response:    k.Response,
+		CreatedAt:   k.CreatedAt,
+	}
+	return ans
+}
+
+func ToEntitiesIdempotencyKey(k IdempotencyKey) entities.IdempotencyKey {
+	ans := entities.IdempotencyKey{
+		ID:          k.ID,
+		UserID:      k.UserID,
+		Key:         k.Key,
+		RequestHash: k.RequestHash,
+		StatusCode:  k.StatusCode,
+		Response:    k.Response,
+		CreatedAt:   k.CreatedAt,
+	}
+	return ans
+}
+
+type BulkOperation struct {
+	bun.BaseModel
+
+	ID        int64 `bun:"id,pk,autoincrement"`
+	UID       uuid.UUID
+	UserID    int64
+	Kind      string
+	Status    int
+	Affected  int64
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func FromEntitiesBulkOperation(o entities.BulkOperation) BulkOperation {
+	ans := BulkOperation{
+		ID:        o.ID,
+		UID:       o.UID,
+		UserID:    o.UserID,
+		Kind:      o.Kind,
+		Status:    int(o.Status),
+		Affected:  o.Affected,
+		Error:     o.Error,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+	return ans
+}
+
+func ToEntitiesBulkOperation(o BulkOperation) entities.BulkOperation {
+	ans := entities.BulkOperation{
+		ID:        o.ID,
+		UID:       o.UID,
+		UserID:    o.UserID,
+		Kind:      o.Kind,
+		Status:    entities.BulkOperationStatus(o.Status),
+		Affected:  o.Affected,
+		Error:     o.Error,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
 	}
 	return ans
 }
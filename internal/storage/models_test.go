@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+func TestScheduledJobEntityRoundTrip(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)
+	j := entities.ScheduledJob{
+		ID:          42,
+		UID:         uuid.New(),
+		UserID:      7,
+		Name:        "reminder",
+		Description: "send a reminder",
+		Url:         "https://example.com/wh",
+		Payload:     `{"a":1}`,
+		ContentType: "application/json",
+		Signature:   "sig",
+		RunAt:       now,
+		Retries:     3,
+		Method:      "PUT",
+		Headers:     map[string]string{"X-A": "1"},
+		Query:       map[string]string{"q": "v"},
+		Schedule:    "0 9 * * *",
+		Timezone:    "Europe/Athens",
+		DSTPolicy:   entities.DSTRunTwice,
+		Interval:    time.Hour,
+		StartAt:     now.Add(-time.Hour),
+		EndAt:       now.Add(24 * time.Hour),
+		MaxRuns:     10,
+		Runs:        2,
+		Tags:        []string{"a", "b"},
+		DedupKey:    "user-7-reminder",
+		DedupPolicy: entities.DedupReject,
+		Status:      entities.Scheduled,
+		Partition:   3,
+		CreatedAt:   now.Add(-2 * time.Hour),
+		UpdatedAt:   now.Add(-time.Minute),
+	}
+	j.SuccessCriteria = entities.SuccessCriteria{
+		SuccessCodes:   []entities.StatusRange{{From: 200, To: 299}, {From: 404, To: 404}},
+		RetryableCodes: []entities.StatusRange{{From: 500, To: 504}},
+		Assertions:     []entities.BodyAssertion{{JSONPath: "$.ok", Equals: "true"}},
+	}
+	j.RetryPolicy = entities.RetryPolicy{
+		MaxAttempts:  5,
+		InitialDelay: 10 * time.Second,
+		Multiplier:   1.5,
+		MaxDelay:     time.Minute,
+		Jitter:       0.1,
+		MaxAge:       time.Hour,
+	}
+	j.Attempts = 1
+	j.RunScheduledAt = now.Add(-30 * time.Second)
+
+	got := ToScheduledJobEntity(FromScheduledJobEntity(j))
+	if !reflect.DeepEqual(got, j) {
+		t.Fatalf("round trip changed the job\n got: %+v\nwant: %+v", got, j)
+	}
+
+	// every field must be set above, so a column that is not mapped in
+	// either direction fails the comparison
+	v := reflect.ValueOf(j)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() {
+			t.Errorf("field %s is not set in the test job", v.Type().Field(i).Name)
+		}
+	}
+}
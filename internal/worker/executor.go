@@ -1,17 +1,19 @@
 package worker
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/rs/zerolog"
 
 	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/delivery"
 	"github.com/gosom/hermeshooks/internal/entities"
+	"github.com/gosom/hermeshooks/internal/schedule"
 	"github.com/gosom/hermeshooks/internal/storage"
 )
 
@@ -21,6 +23,14 @@ type executor struct {
 	iq      <-chan entities.ScheduledJob
 	threads int
 	client  common.HTTPClient
+	signer  delivery.Signer
+	users   *userCache
+	// worker and partition identify who sent the requests
+	worker    string
+	partition int
+	// bodyLimit is the number of bytes of the response bodies that are
+	// stored, 0 stores none
+	bodyLimit int64
 }
 
 func (e executor) start(ctx context.Context) error {
@@ -45,69 +55,147 @@ func (e executor) start(ctx context.Context) error {
 	return nil
 }
 
+// process sends one attempt of the job. A failed attempt that can be
+// retried reschedules the job for the next attempt of its retry policy,
+// otherwise the run ends and recurring jobs move to their next run.
 func (e executor) process(ctx context.Context, job entities.ScheduledJob) error {
-	statusCode, err := func() (int, error) {
+	if job.RunScheduledAt.IsZero() {
+		job.RunScheduledAt = job.RunAt
+	}
+	recorder := delivery.Recorder{
+		Client:    e.client,
+		BodyLimit: e.bodyLimit,
+		Criteria:  job.SuccessCriteria,
+		Attempt:   entities.Execution{Attempt: job.Attempts + 1},
+	}
+	var retryable bool
+	err := func() error {
 		req, err := e.prepareReq(ctx, job)
 		if err != nil {
-			return 0, fmt.Errorf("fail to prepare req error: %w", err)
+			return fmt.Errorf("fail to prepare req error: %w", err)
 		}
 		e.log.Info().Msgf("prepared req for job %d", job.ID)
-		resp, err := common.RetryDo(e.client, req, job.Retries)
-		if err != nil {
-			return 0, fmt.Errorf("request fail with error: %w", err)
-		}
-		if resp == nil {
-			return 0, fmt.Errorf("resp is nil")
-		}
-		io.Copy(io.Discard, resp.Body)
-		resp.Body.Close()
-		return resp.StatusCode, nil
+		retryable, err = recorder.Send(req)
+		return err
 	}()
 
-	e.log.Info().Int64("jobId", job.ID).Err(err).Msg("process job")
-	_ = statusCode
+	e.log.Info().Int64("jobId", job.ID).Int("attempt", job.Attempts+1).Int("statusCode", recorder.Attempt.StatusCode).Err(err).Msg("process job")
 
-	job.UpdatedAt = time.Now().UTC()
-	var msg string
-	if err != nil {
-		job.Status = entities.Fail
-		msg = err.Error()
-	} else {
-		job.Status = entities.Success
-	}
-	execution := entities.Execution{
-		ScheduledJobID: job.ID,
-		StatusCode:     statusCode,
-		Msg:            msg,
-		CreatedAt:      job.UpdatedAt,
+	now := time.Now().UTC()
+	execution := recorder.Attempt
+	if !recorder.Sent {
+		// the request was never sent
+		execution.Msg = err.Error()
+		execution.ErrorClass = entities.ErrorClassRequest
+		execution.CreatedAt = now
 	}
+	execution.ScheduledJobID = job.ID
+	execution.Run = job.Runs + 1
+	execution.Worker = e.worker
+	execution.Partition = e.partition
+	execution.Msg = truncate(execution.Msg, maxExecutionMsg)
+	success := err == nil
+
 	tx, err := e.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
-	if err := storage.UpdateJobStatus(ctx, tx, job); err != nil {
+	// a job with the same dedup key may have replaced the job while the
+	// request was sent
+	superseded, err := storage.IsJobSupersededForUpdate(ctx, tx, job.ID)
+	if err != nil {
+		return err
+	}
+	paused, err := storage.IsUserPausedForShare(ctx, tx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	job.UpdatedAt = now
+	job.Attempts++
+	var (
+		next  time.Time
+		retry bool
+	)
+	if !success && retryable && !superseded {
+		next, retry = job.Retry().NextAttempt(job.Attempts, job.RunScheduledAt, now, execution.RetryAfter)
+	}
+	if retry {
+		job.Status = entities.Scheduled
+		job.RunAt = next
+	} else {
+		e.endRun(&job, success, !superseded, now)
+	}
+	holdIfPaused(&job, paused)
+	if err := storage.UpdateJobAfterRun(ctx, tx, job); err != nil {
 		return err
 	}
-	if _, err := storage.InsertExecution(ctx, tx, execution); err != nil {
+	if err := storage.InsertExecutions(ctx, tx, []entities.Execution{execution}); err != nil {
 		return err
 	}
 	return tx.Commit()
 }
 
+// endRun records the outcome of the run and, unless next is false,
+// schedules the next run of a recurring job
+func (e executor) endRun(job *entities.ScheduledJob, success, next bool, now time.Time) {
+	if success {
+		job.Status = entities.Success
+	} else {
+		job.Status = entities.Fail
+	}
+	job.Runs++
+	after := job.RunScheduledAt
+	if now.After(after) {
+		after = now
+	}
+	job.Attempts = 0
+	job.RunScheduledAt = time.Time{}
+	if !next {
+		return
+	}
+	if runAt, ok := schedule.Next(*job, after); ok {
+		job.Status = entities.Scheduled
+		job.RunAt = runAt
+	}
+}
+
+// holdIfPaused pauses a job that would run again when its account was
+// paused while the job was pending
+func holdIfPaused(job *entities.ScheduledJob, paused bool) {
+	if paused && job.Status == entities.Scheduled {
+		job.Status = entities.Paused
+	}
+}
+
 func (e executor) prepareReq(ctx context.Context, job entities.ScheduledJob) (*http.Request, error) {
-	var body io.Reader
-	if len(job.Payload) > 0 {
-		body = bytes.NewReader([]byte(job.Payload))
+	req, err := delivery.NewRequest(ctx, job)
+	if err != nil {
+		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.Url, body)
+	user, err := e.users.get(ctx, job.UserID)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", job.ContentType)
-	req.Header.Set("X-HERMESHOOKS-PAYLOAD-SIG", job.Signature)
-	// TODO compute our sig using our PrivateKey
-	signature := ""
-	req.Header.Set("X-HERMESHOOKS-SIG", signature)
+	if err := e.signer.Sign(req, user, []byte(job.Payload), time.Now().UTC()); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
+
+// maxExecutionMsg is the size of the msg column of the executions
+const maxExecutionMsg = 255
+
+// truncate cuts s to at most n bytes without splitting a rune. Invalid
+// UTF-8, which Postgres rejects, is replaced.
+func truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if len(s) > n {
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n]
+	}
+	return s
+}
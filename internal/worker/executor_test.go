@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gosom/hermeshooks/internal/entities"
+)
+
+func TestEndRunSuperseded(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	job := entities.ScheduledJob{
+		Status:   entities.Pending,
+		Interval: time.Hour,
+		RunAt:    now,
+	}
+	var e executor
+
+	running := job
+	e.endRun(&running, true, true, now)
+	if running.Status != entities.Scheduled || !running.RunAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("recurring job was not rescheduled: %s at %s", running.Status, running.RunAt)
+	}
+
+	// a job replaced while pending keeps the outcome of its last run
+	superseded := job
+	e.endRun(&superseded, false, false, now)
+	if superseded.Status != entities.Fail || superseded.Runs != 1 {
+		t.Fatalf("superseded job ended as %s after %d runs", superseded.Status, superseded.Runs)
+	}
+}
+
+func TestHoldIfPaused(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var e executor
+
+	// the account was paused while the job was pending
+	recurring := entities.ScheduledJob{Status: entities.Pending, Interval: time.Hour, RunAt: now}
+	e.endRun(&recurring, true, true, now)
+	holdIfPaused(&recurring, true)
+	if recurring.Status != entities.Paused || !recurring.RunAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("recurring job is %s at %s, want paused at the next run", recurring.Status, recurring.RunAt)
+	}
+
+	once := entities.ScheduledJob{Status: entities.Pending, RunAt: now}
+	e.endRun(&once, true, true, now)
+	holdIfPaused(&once, true)
+	if once.Status != entities.Success {
+		t.Fatalf("finished job is %s, want success", once.Status)
+	}
+
+	resumed := entities.ScheduledJob{Status: entities.Scheduled, RunAt: now}
+	holdIfPaused(&resumed, false)
+	if resumed.Status != entities.Scheduled {
+		t.Fatalf("job of an active account is %s", resumed.Status)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel"},
+		{"αβγ", 4, "αβ"},
+		{"αβγ", 3, "α"},
+		{"αβγ", 1, ""},
+		{"a😀b", 4, "a"},
+		{"a😀b", 5, "a😀"},
+		{"ok\xffok", 10, "ok\uFFFDok"},
+		{"ok\xffok", 4, "ok"},
+		{"ok\xffok", 5, "ok\uFFFD"},
+		{strings.Repeat("a", 254) + "\xff\xff", 255, strings.Repeat("a", 254)},
+		{strings.Repeat("a", 253) + "\xff", 255, strings.Repeat("a", 253)},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, %d) is not valid UTF-8", tt.s, tt.n)
+		}
+	}
+}
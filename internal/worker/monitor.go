@@ -60,7 +60,7 @@ func (m monitor) start(ctx context.Context) (<-chan entities.ScheduledJob, <-cha
 					outc <- jobs[i]
 				}
 			}()
-			m.log.Info().Str("monitor", "yes").Msgf("next job %+b", next)
+			m.log.Info().Str("monitor", "yes").Msgf("next job %+v", next)
 			waitTime := defaultWaitDuration
 			if !next.RunAt.IsZero() {
 				switch wt := next.RunAt.Sub(now); {
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gosom/hermeshooks/internal/entities"
+	"github.com/gosom/hermeshooks/internal/storage"
+)
+
+// userCacheTTL is how long a user is kept. A rotated signing secret is
+// used once the cached user expires, long before the old secret stops
+// being accepted.
+const userCacheTTL = 30 * time.Second
+
+// userCache keeps the users that own the executed jobs so every attempt
+// does not query them
+type userCache struct {
+	db    *storage.DB
+	ttl   time.Duration
+	mu    sync.Mutex
+	users map[int64]cachedUser
+}
+
+type cachedUser struct {
+	user      entities.User
+	expiresAt time.Time
+}
+
+func newUserCache(db *storage.DB, ttl time.Duration) *userCache {
+	ans := userCache{
+		db:    db,
+		ttl:   ttl,
+		users: make(map[int64]cachedUser),
+	}
+	return &ans
+}
+
+func (c *userCache) get(ctx context.Context, id int64) (entities.User, error) {
+	now := time.Now()
+	c.mu.Lock()
+	cached, ok := c.users[id]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.user, nil
+	}
+	u, err := storage.GetUserByID(ctx, c.db, id)
+	if err != nil {
+		return u, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, v := range c.users {
+		if now.After(v.expiresAt) {
+			delete(c.users, k)
+		}
+	}
+	c.users[id] = cachedUser{user: u, expiresAt: now.Add(c.ttl)}
+	return u, nil
+}
@@ -3,6 +3,7 @@ package worker
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gosom/hermeshooks/internal/common"
+	"github.com/gosom/hermeshooks/internal/delivery"
 	"github.com/gosom/hermeshooks/internal/storage"
 	"github.com/rs/zerolog"
 )
@@ -23,6 +25,12 @@ type WorkerConfig struct {
 	DB          *storage.DB
 	Concurrency int
 	ApiKey      string
+	// SigningKey signs the outgoing requests when set
+	SigningKey *ecdsa.PrivateKey
+	// ResponseBodyLimit is the number of bytes of each response body that
+	// is stored with the execution. 0 selects 4KB and a negative value
+	// stores none.
+	ResponseBodyLimit int64
 }
 
 type worker struct {
@@ -33,6 +41,8 @@ type worker struct {
 	db          *storage.DB
 	concurrency int
 	apiKey      string
+	signer      delivery.Signer
+	bodyLimit   int64
 }
 
 func NewWorker(cfg WorkerConfig) (*worker, error) {
@@ -50,6 +60,13 @@ func NewWorker(cfg WorkerConfig) (*worker, error) {
 	if cfg.Concurrency == 0 {
 		cfg.Concurrency = 4
 	}
+	if cfg.ResponseBodyLimit == 0 {
+		cfg.ResponseBodyLimit = 4 << 10
+	}
+	signer, err := delivery.NewSigner(cfg.SigningKey)
+	if err != nil {
+		return nil, err
+	}
 	ans := worker{
 		name:        uuid.New().String(),
 		log:         cfg.Log,
@@ -58,6 +75,8 @@ func NewWorker(cfg WorkerConfig) (*worker, error) {
 		db:          cfg.DB,
 		concurrency: cfg.Concurrency,
 		apiKey:      cfg.ApiKey,
+		signer:      signer,
+		bodyLimit:   cfg.ResponseBodyLimit,
 	}
 	return &ans, nil
 }
@@ -82,11 +101,16 @@ func (w *worker) Start(ctx context.Context) error {
 	jobsc, errc2 := m.start(ctx)
 
 	ex := executor{
-		log:     w.log,
-		db:      w.db,
-		iq:      jobsc,
-		threads: w.concurrency,
-		client:  w.netClient,
+		log:       w.log,
+		db:        w.db,
+		iq:        jobsc,
+		threads:   w.concurrency,
+		client:    w.netClient,
+		signer:    w.signer,
+		users:     newUserCache(w.db, userCacheTTL),
+		worker:    w.name,
+		partition: partition,
+		bodyLimit: w.bodyLimit,
 	}
 
 	errc3 := func() <-chan error {
@@ -127,7 +151,6 @@ func (w *worker) Start(ctx context.Context) error {
 	case err := <-errc4:
 		return err
 	}
-	return nil
 }
 
 func (w *worker) register(ctx context.Context) (int, error) {
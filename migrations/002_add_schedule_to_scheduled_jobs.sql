@@ -0,0 +1,7 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN schedule VARCHAR(128) NOT NULL DEFAULT '';
+
+---- create above / drop below ----
+
+ALTER TABLE scheduled_jobs DROP COLUMN schedule;
@@ -0,0 +1,9 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE scheduled_jobs ADD COLUMN dst_policy INT NOT NULL DEFAULT 0;
+
+---- create above / drop below ----
+
+ALTER TABLE scheduled_jobs DROP COLUMN dst_policy;
+ALTER TABLE scheduled_jobs DROP COLUMN timezone;
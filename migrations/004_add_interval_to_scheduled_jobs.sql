@@ -0,0 +1,15 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN interval_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE scheduled_jobs ADD COLUMN start_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE scheduled_jobs ADD COLUMN end_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE scheduled_jobs ADD COLUMN max_runs INT NOT NULL DEFAULT 0;
+ALTER TABLE scheduled_jobs ADD COLUMN runs INT NOT NULL DEFAULT 0;
+
+---- create above / drop below ----
+
+ALTER TABLE scheduled_jobs DROP COLUMN runs;
+ALTER TABLE scheduled_jobs DROP COLUMN max_runs;
+ALTER TABLE scheduled_jobs DROP COLUMN end_at;
+ALTER TABLE scheduled_jobs DROP COLUMN start_at;
+ALTER TABLE scheduled_jobs DROP COLUMN interval_seconds;
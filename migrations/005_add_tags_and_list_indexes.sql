@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_scheduled_jobs_user_run_at ON scheduled_jobs(user_id, run_at, id);
+CREATE INDEX idx_scheduled_jobs_user_created_at ON scheduled_jobs(user_id, created_at);
+CREATE INDEX idx_scheduled_jobs_user_name ON scheduled_jobs(user_id, name varchar_pattern_ops);
+CREATE INDEX idx_scheduled_jobs_tags ON scheduled_jobs USING GIN(tags);
+
+---- create above / drop below ----
+
+DROP INDEX idx_scheduled_jobs_tags;
+DROP INDEX idx_scheduled_jobs_user_name;
+DROP INDEX idx_scheduled_jobs_user_created_at;
+DROP INDEX idx_scheduled_jobs_user_run_at;
+
+ALTER TABLE scheduled_jobs DROP COLUMN tags;
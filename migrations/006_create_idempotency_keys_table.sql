@@ -0,0 +1,23 @@
+-- Write your migrate up statements here
+
+CREATE TABLE idempotency_keys (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INT NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(44) NOT NULL,
+    status_code INT NOT NULL,
+    response TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT fk_users
+      FOREIGN KEY(user_id)
+      REFERENCES users(id),
+    CONSTRAINT uq_idempotency_keys_user_key UNIQUE(user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+---- create above / drop below ----
+
+DROP INDEX idx_idempotency_keys_created_at;
+
+DROP TABLE idempotency_keys;
@@ -0,0 +1,18 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN dedup_key VARCHAR(128);
+ALTER TABLE scheduled_jobs ADD COLUMN dedup_policy INT NOT NULL DEFAULT 0;
+-- set on pending jobs that a job with the same dedup key replaced
+ALTER TABLE scheduled_jobs ADD COLUMN superseded BOOLEAN NOT NULL DEFAULT false;
+
+-- only one scheduled or pending job per user and dedup key
+CREATE UNIQUE INDEX uq_scheduled_jobs_user_dedup_key ON scheduled_jobs(user_id, dedup_key)
+    WHERE dedup_key IS NOT NULL AND status IN (1, 2);
+
+---- create above / drop below ----
+
+DROP INDEX uq_scheduled_jobs_user_dedup_key;
+
+ALTER TABLE scheduled_jobs DROP COLUMN superseded;
+ALTER TABLE scheduled_jobs DROP COLUMN dedup_policy;
+ALTER TABLE scheduled_jobs DROP COLUMN dedup_key;
@@ -0,0 +1,24 @@
+-- Write your migrate up statements here
+
+CREATE TABLE bulk_operations (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    uid UUID NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    status INT NOT NULL,
+    affected BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT fk_users
+      FOREIGN KEY(user_id)
+      REFERENCES users(id)
+);
+
+-- a user can run one bulk operation (status 1) at a time
+CREATE UNIQUE INDEX uq_bulk_operations_user_running ON bulk_operations(user_id)
+    WHERE status = 1;
+
+---- create above / drop below ----
+
+DROP TABLE bulk_operations;
@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+
+-- paused jobs (status 6) keep their dedup key reserved
+DROP INDEX uq_scheduled_jobs_user_dedup_key;
+CREATE UNIQUE INDEX uq_scheduled_jobs_user_dedup_key ON scheduled_jobs(user_id, dedup_key)
+    WHERE dedup_key IS NOT NULL AND status IN (1, 2, 6);
+
+-- jobs of paused accounts are paused when they are created or run again
+ALTER TABLE users ADD COLUMN paused BOOLEAN NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE users DROP COLUMN paused;
+
+DROP INDEX uq_scheduled_jobs_user_dedup_key;
+CREATE UNIQUE INDEX uq_scheduled_jobs_user_dedup_key ON scheduled_jobs(user_id, dedup_key)
+    WHERE dedup_key IS NOT NULL AND status IN (1, 2);
@@ -0,0 +1,9 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN method VARCHAR(7) NOT NULL DEFAULT 'POST';
+ALTER TABLE scheduled_jobs ADD COLUMN request_options JSONB NOT NULL DEFAULT '{}';
+
+---- create above / drop below ----
+
+ALTER TABLE scheduled_jobs DROP COLUMN request_options;
+ALTER TABLE scheduled_jobs DROP COLUMN method;
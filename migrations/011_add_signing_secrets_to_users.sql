@@ -0,0 +1,14 @@
+-- Write your migrate up statements here
+
+ALTER TABLE users ADD COLUMN signing_secret VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN previous_signing_secret VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN previous_signing_secret_expires_at TIMESTAMP WITH TIME ZONE;
+
+-- existing users get a random secret, they can rotate it to a new one
+UPDATE users SET signing_secret = 'whsec_' || replace(uuid_generate_v4()::text || uuid_generate_v4()::text, '-', '');
+
+---- create above / drop below ----
+
+ALTER TABLE users DROP COLUMN previous_signing_secret_expires_at;
+ALTER TABLE users DROP COLUMN previous_signing_secret;
+ALTER TABLE users DROP COLUMN signing_secret;
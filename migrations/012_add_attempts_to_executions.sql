@@ -0,0 +1,20 @@
+-- Write your migrate up statements here
+
+-- existing rows summarize all the attempts of a run
+ALTER TABLE executions ADD COLUMN run INT NOT NULL DEFAULT 0;
+ALTER TABLE executions ADD COLUMN attempt INT NOT NULL DEFAULT 1;
+ALTER TABLE executions ADD COLUMN error_class INT NOT NULL DEFAULT 0;
+ALTER TABLE executions ADD COLUMN started_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE executions ADD COLUMN finished_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE executions ADD COLUMN worker VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE executions ADD COLUMN partition INT NOT NULL DEFAULT 0;
+
+---- create above / drop below ----
+
+ALTER TABLE executions DROP COLUMN partition;
+ALTER TABLE executions DROP COLUMN worker;
+ALTER TABLE executions DROP COLUMN finished_at;
+ALTER TABLE executions DROP COLUMN started_at;
+ALTER TABLE executions DROP COLUMN error_class;
+ALTER TABLE executions DROP COLUMN attempt;
+ALTER TABLE executions DROP COLUMN run;
@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+
+ALTER TABLE executions ADD COLUMN response_headers JSONB;
+ALTER TABLE executions ADD COLUMN response_body TEXT NOT NULL DEFAULT '';
+ALTER TABLE executions ADD COLUMN response_truncated BOOLEAN NOT NULL DEFAULT false;
+
+-- used to clear the responses after the retention period
+CREATE INDEX idx_executions_response_created_at ON executions(created_at)
+  WHERE response_headers IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX idx_executions_response_created_at;
+
+ALTER TABLE executions DROP COLUMN response_truncated;
+ALTER TABLE executions DROP COLUMN response_body;
+ALTER TABLE executions DROP COLUMN response_headers;
@@ -0,0 +1,7 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN success_criteria JSONB NOT NULL DEFAULT '{}';
+
+---- create above / drop below ----
+
+ALTER TABLE scheduled_jobs DROP COLUMN success_criteria;
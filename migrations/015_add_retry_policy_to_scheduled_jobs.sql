@@ -0,0 +1,11 @@
+-- Write your migrate up statements here
+
+ALTER TABLE scheduled_jobs ADD COLUMN retry_policy JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE scheduled_jobs ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE scheduled_jobs ADD COLUMN run_scheduled_at TIMESTAMP WITH TIME ZONE;
+
+---- create above / drop below ----
+
+ALTER TABLE scheduled_jobs DROP COLUMN run_scheduled_at;
+ALTER TABLE scheduled_jobs DROP COLUMN attempts;
+ALTER TABLE scheduled_jobs DROP COLUMN retry_policy;
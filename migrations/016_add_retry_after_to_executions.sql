@@ -0,0 +1,7 @@
+-- Write your migrate up statements here
+
+ALTER TABLE executions ADD COLUMN retry_after TIMESTAMP WITH TIME ZONE;
+
+---- create above / drop below ----
+
+ALTER TABLE executions DROP COLUMN retry_after;
@@ -0,0 +1,171 @@
+// Package client is a Go client for the hermeshooks REST API.
+//
+//	c := client.New("http://localhost:8000", client.WithAPIKey(apiKey))
+//	created, err := c.CreateScheduledJob(ctx, client.ScheduledJobsPayload{
+//		Name:  "reminder",
+//		Url:   "https://example.com/webhook",
+//		RunAt: time.Now().Add(time.Hour),
+//	})
+//
+// GET, PATCH and DELETE requests and job creations, which carry an
+// idempotency key, are retried with an exponential backoff when they fail
+// with a network error or a 5xx status. Other requests are sent once, since
+// sending them again could repeat their effect. Responses with other unexpected status codes are
+// returned as *Error values that match ErrNotFound, ErrConflict and the
+// other sentinel errors with errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosom/hermeshooks/internal/common"
+)
+
+const defaultRetries = 3
+
+// Doer sends HTTP requests. *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    Doer
+	retries int
+}
+
+type Option func(*Client)
+
+// WithAPIKey authenticates the requests. Use the internal api key to call
+// CreateUser.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+func WithHTTPClient(d Doer) Option {
+	return func(c *Client) {
+		c.http = d
+	}
+}
+
+// WithRetries sets how many times a request that can be retried is sent at
+// most. 1 disables retries.
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+// New returns a client for the server at baseURL, like http://localhost:8000
+func New(baseURL string, opts ...Option) *Client {
+	c := Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		retries: defaultRetries,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// do sends in as JSON and decodes the response into out when the status
+// code is the expected one
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, in, out any, expected int) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1"+path, body)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = query.Encode()
+	for k := range header {
+		req.Header.Set(k, header.Get(k))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(c.apiKey) > 0 {
+		req.Header.Set("X-API-KEY", c.apiKey)
+	}
+	retries := 1
+	if isRetryable(req) {
+		retries = c.retries
+	}
+	resp, err := common.RetryDo(c.http, req, retries)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if resp.StatusCode != expected {
+		apiErr := Error{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return &apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// isRetryable reports whether sending the request again has the same effect
+// as sending it once
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return len(req.Header.Get("Idempotency-Key")) > 0
+}
+
+// CreateUser registers a user and returns its api key and signing secret.
+// It requires a client with the internal api key.
+func (c *Client) CreateUser(ctx context.Context, username string) (SignupResponse, error) {
+	var ans SignupResponse
+	err := c.do(ctx, http.MethodPost, "/users", nil, nil, SignupPayload{Username: username}, &ans, http.StatusCreated)
+	return ans, err
+}
+
+func (c *Client) GetSigningSecret(ctx context.Context) (SigningSecretResponse, error) {
+	var ans SigningSecretResponse
+	err := c.do(ctx, http.MethodGet, "/account/signingSecret", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+func (c *Client) RotateSigningSecret(ctx context.Context) (SigningSecretResponse, error) {
+	var ans SigningSecretResponse
+	err := c.do(ctx, http.MethodPost, "/account/signingSecret/rotate", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+// Meta returns the public keys that sign the webhook requests
+func (c *Client) Meta(ctx context.Context) (MetaResponse, error) {
+	var ans MetaResponse
+	err := c.do(ctx, http.MethodGet, "/meta", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+func (c *Client) Health(ctx context.Context) (HealthResponse, error) {
+	var ans HealthResponse
+	err := c.do(ctx, http.MethodGet, "/health", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// failingDoer fails every request with a network error
+type failingDoer struct {
+	calls int32
+}
+
+func (d *failingDoer) Do(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&d.calls, 1)
+	return nil, errors.New("connection reset")
+}
+
+func TestClientRetriesOnlyIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(c *Client) error
+		wantCalls int32
+	}{
+		{"get", func(c *Client) error {
+			_, err := c.GetScheduledJob(context.Background(), "uid")
+			return err
+		}, 2},
+		{"create", func(c *Client) error {
+			_, err := c.CreateScheduledJob(context.Background(), ScheduledJobsPayload{})
+			return err
+		}, 2},
+		{"trigger", func(c *Client) error {
+			_, err := c.TriggerScheduledJob(context.Background(), "uid")
+			return err
+		}, 1},
+		{"replay", func(c *Client) error {
+			_, err := c.ReplayScheduledJob(context.Background(), "uid")
+			return err
+		}, 1},
+		{"batch", func(c *Client) error {
+			_, err := c.CreateScheduledJobs(context.Background(), nil)
+			return err
+		}, 1},
+		{"test delivery", func(c *Client) error {
+			_, err := c.TestDelivery(context.Background(), ScheduledJobsPayload{})
+			return err
+		}, 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d := failingDoer{}
+			c := New("http://localhost", WithHTTPClient(&d), WithRetries(2))
+			if err := tt.call(c); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := atomic.LoadInt32(&d.calls); got != tt.wantCalls {
+				t.Fatalf("sent %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListDeadLetters returns the failed jobs with the attempts of their last
+// run. The status of the filter must be empty.
+func (c *Client) ListDeadLetters(ctx context.Context, o ListOptions) (DeadLetterListResponse, error) {
+	var ans DeadLetterListResponse
+	err := c.do(ctx, http.MethodGet, "/deadLetters", o.query(), nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+// Redrive schedules a new run of a failed job, optionally to a new url or
+// after a delay
+func (c *Client) Redrive(ctx context.Context, uid string, p RedrivePayload) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	err := c.do(ctx, http.MethodPost, "/deadLetters/"+url.PathEscape(uid)+"/redrive", nil, nil, p, &ans, http.StatusOK)
+	return ans, err
+}
+
+// RedriveMatching starts redriving the failed jobs that match the filter in
+// the background. Poll the returned operation with GetOperation.
+func (c *Client) RedriveMatching(ctx context.Context, p RedriveMatchingPayload) (BulkOperationResponse, error) {
+	var ans BulkOperationResponse
+	err := c.do(ctx, http.MethodPost, "/deadLetters:redrive", nil, nil, p, &ans, http.StatusAccepted)
+	return ans, err
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrValidation    = errors.New("validation error")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrNotFound      = errors.New("not found")
+	ErrConflict      = errors.New("conflict")
+	ErrUnprocessable = errors.New("unprocessable request")
+)
+
+// Error is returned for responses with an unexpected status code. It matches
+// the sentinel error of its status code with errors.Is.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("hermeshooks: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("hermeshooks: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnprocessable:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
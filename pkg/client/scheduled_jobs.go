@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateScheduledJob schedules a job. Unless p has an idempotency key, a
+// random one is used so that retries do not create the job twice.
+func (c *Client) CreateScheduledJob(ctx context.Context, p ScheduledJobsPayload) (ScheduledJobResponse, error) {
+	key := p.IdempotencyKey
+	if len(key) == 0 {
+		key = uuid.New().String()
+	}
+	header := http.Header{}
+	header.Set("Idempotency-Key", key)
+	var ans ScheduledJobResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs", nil, header, p, &ans, http.StatusCreated)
+	return ans, err
+}
+
+// CreateScheduledJobs schedules many jobs with one request. Batches have no
+// idempotency key so the request is not retried. Set a dedupKey on the items
+// to send it again safely.
+func (c *Client) CreateScheduledJobs(ctx context.Context, items []ScheduledJobsPayload) (ScheduledJobsBatchResponse, error) {
+	var ans ScheduledJobsBatchResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs:batch", nil, nil, ScheduledJobsBatchPayload{Items: items}, &ans, http.StatusOK)
+	return ans, err
+}
+
+// TestDelivery sends the request of the job right away without scheduling
+// it and returns the request with the response of the receiver
+func (c *Client) TestDelivery(ctx context.Context, p ScheduledJobsPayload) (TestDeliveryResponse, error) {
+	var ans TestDeliveryResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs:test", nil, nil, p, &ans, http.StatusOK)
+	return ans, err
+}
+
+func (c *Client) GetScheduledJob(ctx context.Context, uid string) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	err := c.do(ctx, http.MethodGet, "/scheduledJobs/"+url.PathEscape(uid), nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+// ListOptions selects a page of jobs. Pass the NextCursor of a page as
+// the Cursor of the next request.
+type ListOptions struct {
+	Filter ScheduledJobsFilterPayload
+	Desc   bool
+	Limit  int
+	Cursor string
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	f := o.Filter
+	for _, st := range f.Status {
+		q.Add("status", st)
+	}
+	for _, tag := range f.Tags {
+		q.Add("tag", tag)
+	}
+	if len(f.NamePrefix) > 0 {
+		q.Set("namePrefix", f.NamePrefix)
+	}
+	if len(f.UrlHost) > 0 {
+		q.Set("urlHost", f.UrlHost)
+	}
+	times := map[string]time.Time{
+		"runAtFrom":   f.RunAtFrom,
+		"runAtTo":     f.RunAtTo,
+		"createdFrom": f.CreatedFrom,
+		"createdTo":   f.CreatedTo,
+	}
+	for k, v := range times {
+		if !v.IsZero() {
+			q.Set(k, v.Format(time.RFC3339Nano))
+		}
+	}
+	if o.Desc {
+		q.Set("order", "desc")
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if len(o.Cursor) > 0 {
+		q.Set("cursor", o.Cursor)
+	}
+	return q
+}
+
+func (c *Client) ListScheduledJobs(ctx context.Context, o ListOptions) (ScheduledJobListResponse, error) {
+	var ans ScheduledJobListResponse
+	err := c.do(ctx, http.MethodGet, "/scheduledJobs", o.query(), nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+func (c *Client) UpdateScheduledJob(ctx context.Context, uid string, p ScheduledJobsPatchPayload) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	err := c.do(ctx, http.MethodPatch, "/scheduledJobs/"+url.PathEscape(uid), nil, nil, p, &ans, http.StatusOK)
+	return ans, err
+}
+
+func (c *Client) CancelScheduledJob(ctx context.Context, uid string) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	err := c.do(ctx, http.MethodDelete, "/scheduledJobs/"+url.PathEscape(uid), nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+func (c *Client) PauseScheduledJob(ctx context.Context, uid string) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs/"+url.PathEscape(uid)+"/pause", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+// ResumeScheduledJob resumes a paused job. missedRunPolicy is one of fire,
+// skip or fail and an empty value selects fire.
+func (c *Client) ResumeScheduledJob(ctx context.Context, uid string, missedRunPolicy string) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	p := ResumePayload{MissedRunPolicy: missedRunPolicy}
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs/"+url.PathEscape(uid)+"/resume", nil, nil, p, &ans, http.StatusOK)
+	return ans, err
+}
+
+// TriggerScheduledJob runs a scheduled job now instead of at its runAt
+func (c *Client) TriggerScheduledJob(ctx context.Context, uid string) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs/"+url.PathEscape(uid)+"/trigger", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+// ReplayScheduledJob sends the request of a job that succeeded or failed
+// again now
+func (c *Client) ReplayScheduledJob(ctx context.Context, uid string) (ScheduledJobGetResponse, error) {
+	var ans ScheduledJobGetResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs/"+url.PathEscape(uid)+"/replay", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+// PauseAll pauses every scheduled job of the account
+func (c *Client) PauseAll(ctx context.Context) (PauseAllResponse, error) {
+	var ans PauseAllResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs:pause", nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
+
+// ResumeAll resumes every paused job of the account
+func (c *Client) ResumeAll(ctx context.Context, missedRunPolicy string) (PauseAllResponse, error) {
+	var ans PauseAllResponse
+	p := ResumePayload{MissedRunPolicy: missedRunPolicy}
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs:resume", nil, nil, p, &ans, http.StatusOK)
+	return ans, err
+}
+
+// CancelMatching starts cancelling the jobs that match the filter in the
+// background. The filter must set at least one field. Poll the returned
+// operation with GetOperation.
+func (c *Client) CancelMatching(ctx context.Context, f ScheduledJobsFilterPayload) (BulkOperationResponse, error) {
+	var ans BulkOperationResponse
+	err := c.do(ctx, http.MethodPost, "/scheduledJobs:cancel", nil, nil, f, &ans, http.StatusAccepted)
+	return ans, err
+}
+
+func (c *Client) GetOperation(ctx context.Context, uid string) (BulkOperationResponse, error) {
+	var ans BulkOperationResponse
+	err := c.do(ctx, http.MethodGet, "/operations/"+url.PathEscape(uid), nil, nil, nil, &ans, http.StatusOK)
+	return ans, err
+}
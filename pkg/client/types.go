@@ -0,0 +1,35 @@
+package client
+
+import "github.com/gosom/hermeshooks/internal/rest"
+
+// The request and response types are the ones the server uses
+type (
+	ScheduledJobsPayload       = rest.ScheduledJobsPayload
+	ScheduledJobsPatchPayload  = rest.ScheduledJobsPatchPayload
+	ScheduledJobsFilterPayload = rest.ScheduledJobsFilterPayload
+	ScheduledJobsBatchPayload  = rest.ScheduledJobsBatchPayload
+	SuccessCriteriaPayload     = rest.SuccessCriteriaPayload
+	BodyAssertionPayload       = rest.BodyAssertionPayload
+	RetryPolicyPayload         = rest.RetryPolicyPayload
+	ScheduledJobResponse       = rest.ScheduledJobResponse
+	ScheduledJobGetResponse    = rest.ScheduledJobGetResponse
+	ScheduledJobListResponse   = rest.ScheduledJobListResponse
+	ScheduledJobsBatchResponse = rest.ScheduledJobsBatchResponse
+	ScheduledJobsBatchItem     = rest.ScheduledJobsBatchItem
+	ExecutionResponse          = rest.ExecutionResponse
+	BulkOperationResponse      = rest.BulkOperationResponse
+	ResumePayload              = rest.ResumePayload
+	DeadLetterResponse         = rest.DeadLetterResponse
+	DeadLetterListResponse     = rest.DeadLetterListResponse
+	RedrivePayload             = rest.RedrivePayload
+	RedriveMatchingPayload     = rest.RedriveMatchingPayload
+	TestDeliveryResponse       = rest.TestDeliveryResponse
+	TestDeliveryRequest        = rest.TestDeliveryRequest
+	PauseAllResponse           = rest.PauseAllResponse
+	SignupPayload              = rest.SignupPayload
+	SignupResponse             = rest.SignupResponse
+	SigningSecretResponse      = rest.SigningSecretResponse
+	MetaResponse               = rest.MetaResponse
+	PublicKeyResponse          = rest.PublicKeyResponse
+	HealthResponse             = rest.HealthResponse
+)
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type metaResponse struct {
+	Keys []struct {
+		KID       string `json:"kid"`
+		PublicKey string `json:"publicKey"`
+	} `json:"keys"`
+}
+
+// FetchPublicKeys downloads the public keys of a hermeshooks server by key
+// id. serverURL is the base url of the server, like https://example.com.
+func FetchPublicKeys(ctx context.Context, client *http.Client, serverURL string) (map[string]*ecdsa.PublicKey, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(serverURL, "/")+"/api/v1/meta", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhook: fetching public keys: unexpected status %d", resp.StatusCode)
+	}
+	var meta metaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, err
+	}
+	ans := make(map[string]*ecdsa.PublicKey, len(meta.Keys))
+	for _, k := range meta.Keys {
+		key, err := ParsePublicKey(k.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: key %s: %w", k.KID, err)
+		}
+		ans[k.KID] = key
+	}
+	return ans, nil
+}
+
+// ParsePublicKey parses a PEM encoded ECDSA public key
+func ParsePublicKey(s string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an ECDSA public key")
+	}
+	return pub, nil
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+type ctxKey struct{}
+
+// FromContext returns the delivery that the middleware verified
+func FromContext(ctx context.Context) (Delivery, bool) {
+	d, ok := ctx.Value(ctxKey{}).(Delivery)
+	return d, ok
+}
+
+// Middleware verifies requests before passing them to next and rejects
+// replays using the seen store of the verifier. Invalid requests get a 401
+// and replays a 409. When next responds with a status other than 2xx the
+// delivery is forgotten so that hermeshooks can retry it.
+// The body of the request is still readable by next.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, v.maxBody+1))
+		if err != nil {
+			http.Error(w, "cannot read body", http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) > v.maxBody {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		d, err := v.Verify(r.Header, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		seen, err := v.seen.Add(r.Context(), d.ID, d.Timestamp.Add(v.tolerance))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if seen {
+			http.Error(w, ErrReplayed.Error(), http.StatusConflict)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		rec := statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(&rec, r.WithContext(context.WithValue(r.Context(), ctxKey{}, d)))
+		if rec.status < 200 || rec.status > 299 {
+			_ = v.seen.Remove(context.Background(), d.ID)
+		}
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.status = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareForgetsFailedDeliveries(t *testing.T) {
+	tests := []struct {
+		status     int
+		wantSecond int
+	}{
+		{http.StatusOK, http.StatusConflict},
+		{http.StatusNoContent, http.StatusConflict},
+		{http.StatusRequestTimeout, http.StatusTooManyRequests},
+		{http.StatusTooManyRequests, http.StatusTooManyRequests},
+		{http.StatusBadRequest, http.StatusTooManyRequests},
+		{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		// the memory store expires ids by the wall clock
+		v := newTestVerifier(t)
+		v.now = time.Now
+		calls := 0
+		h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(tt.status)
+				return
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		body := []byte(`{"a":1}`)
+		header := signedHeader("job-1", time.Now(), body, testSecret)
+		for i, want := range []int{tt.status, tt.wantSecond} {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			req.Header = header.Clone()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != want {
+				t.Errorf("status %d, request %d: got %d, want %d", tt.status, i+1, rec.Code, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SeenStore remembers the deliveries a receiver has accepted.
+// Implementations backed by a shared database or cache let several
+// instances of a receiver reject replays together.
+type SeenStore interface {
+	// Add records the id until expiresAt and reports whether it was
+	// already recorded
+	Add(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+	// Remove forgets the id so that a retry of a failed delivery is accepted
+	Remove(ctx context.Context, id string) error
+}
+
+// MemoryStore is a SeenStore for a single process
+type MemoryStore struct {
+	mu        sync.Mutex
+	ids       map[string]time.Time
+	lastPurge time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		ids: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryStore) Add(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPurge) > time.Minute {
+		for k, exp := range s.ids {
+			if now.After(exp) {
+				delete(s.ids, k)
+			}
+		}
+		s.lastPurge = now
+	}
+	if exp, ok := s.ids[id]; ok && now.Before(exp) {
+		return true, nil
+	}
+	s.ids[id] = expiresAt
+	return false, nil
+}
+
+func (s *MemoryStore) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ids, id)
+	return nil
+}
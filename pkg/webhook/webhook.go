@@ -0,0 +1,221 @@
+// Package webhook verifies the requests that hermeshooks sends to webhook
+// receivers.
+//
+// Every request carries the X-HERMESHOOKS-TIMESTAMP header and is signed
+// over timestamp + "." + job id + "." + body, with the job id of the
+// X-HERMESHOOKS-JOB-ID header, with HMAC-SHA256 using the signing secret of
+// the account (X-HERMESHOOKS-SIGNATURE) and with the ECDSA key of the
+// service (X-HERMESHOOKS-SIG and X-HERMESHOOKS-KEY-ID). A Verifier accepts
+// a request when either signature is valid for the secrets or the public
+// keys it is configured with.
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderTimestamp   = "X-Hermeshooks-Timestamp"
+	HeaderSignature   = "X-Hermeshooks-Signature"
+	HeaderSig         = "X-Hermeshooks-Sig"
+	HeaderKeyID       = "X-Hermeshooks-Key-Id"
+	HeaderPayloadSig  = "X-Hermeshooks-Payload-Sig"
+	HeaderJobID       = "X-Hermeshooks-Job-Id"
+	HeaderAttempt     = "X-Hermeshooks-Attempt"
+	HeaderScheduledAt = "X-Hermeshooks-Scheduled-At"
+
+	// DefaultTolerance is the maximum age of a request's timestamp
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrNoVerificationKeys = errors.New("webhook: a signing secret or a public key is required")
+	ErrMissingSignature   = errors.New("webhook: missing signature")
+	ErrInvalidSignature   = errors.New("webhook: invalid signature")
+	ErrInvalidTimestamp   = errors.New("webhook: invalid timestamp")
+	ErrTimestampExpired   = errors.New("webhook: timestamp outside the tolerance")
+	ErrReplayed           = errors.New("webhook: request was already received")
+)
+
+// Delivery describes a verified request. All its fields are covered by
+// the signature.
+type Delivery struct {
+	// ID identifies the request. It is the hex encoded SHA-256 of the
+	// signed content, so it differs between jobs and between attempts,
+	// which are signed again with a new timestamp.
+	ID string
+	// JobUID is the uid of the scheduled job
+	JobUID string
+	// Timestamp is the signed time the request was created
+	Timestamp time.Time
+	Body      []byte
+}
+
+// Metadata holds the delivery headers that are not part of the signed
+// content. Anyone who sees a signed request can resend it with different
+// values, so use them for logging and not to make decisions.
+type Metadata struct {
+	// Attempt starts from 1 and increases on every retry of the request
+	Attempt int
+	// ScheduledAt is the time the job was scheduled to run
+	ScheduledAt time.Time
+	// PayloadSignature is the signature given when the job was created
+	PayloadSignature string
+}
+
+// ParseMetadata parses the unsigned delivery headers of a request
+func ParseMetadata(h http.Header) Metadata {
+	var m Metadata
+	m.Attempt, _ = strconv.Atoi(h.Get(HeaderAttempt))
+	m.ScheduledAt, _ = time.Parse(time.RFC3339, h.Get(HeaderScheduledAt))
+	m.PayloadSignature = h.Get(HeaderPayloadSig)
+	return m
+}
+
+type Verifier struct {
+	secrets    []string
+	publicKeys map[string]*ecdsa.PublicKey
+	tolerance  time.Duration
+	seen       SeenStore
+	maxBody    int64
+	now        func() time.Time
+}
+
+type Option func(*Verifier)
+
+// WithSecrets verifies the HMAC signatures with the given signing secrets.
+// Pass both the old and the new secret while rotating.
+func WithSecrets(secrets ...string) Option {
+	return func(v *Verifier) {
+		v.secrets = append(v.secrets, secrets...)
+	}
+}
+
+// WithPublicKeys verifies the ECDSA signatures with the given public keys
+// by key id, as published on /api/v1/meta. See FetchPublicKeys.
+func WithPublicKeys(keys map[string]*ecdsa.PublicKey) Option {
+	return func(v *Verifier) {
+		for kid, key := range keys {
+			v.publicKeys[kid] = key
+		}
+	}
+}
+
+// WithTolerance sets the maximum age of a request's timestamp
+func WithTolerance(d time.Duration) Option {
+	return func(v *Verifier) {
+		v.tolerance = d
+	}
+}
+
+// WithSeenStore sets the store the middleware uses to reject replays.
+// By default an in memory store is used.
+func WithSeenStore(s SeenStore) Option {
+	return func(v *Verifier) {
+		v.seen = s
+	}
+}
+
+// WithMaxBodySize limits the size of the body the middleware reads
+func WithMaxBodySize(n int64) Option {
+	return func(v *Verifier) {
+		v.maxBody = n
+	}
+}
+
+func NewVerifier(opts ...Option) (*Verifier, error) {
+	v := Verifier{
+		publicKeys: make(map[string]*ecdsa.PublicKey),
+		tolerance:  DefaultTolerance,
+		maxBody:    4 << 20,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(&v)
+	}
+	if len(v.secrets) == 0 && len(v.publicKeys) == 0 {
+		return nil, ErrNoVerificationKeys
+	}
+	if v.seen == nil {
+		v.seen = NewMemoryStore()
+	}
+	return &v, nil
+}
+
+// Verify checks the signatures and the timestamp of a request. It does not
+// check for replays.
+func (v *Verifier) Verify(h http.Header, body []byte) (Delivery, error) {
+	var d Delivery
+	ts := h.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return d, ErrInvalidTimestamp
+	}
+	d.Timestamp = time.Unix(unix, 0).UTC()
+	if age := v.now().Sub(d.Timestamp); age > v.tolerance || age < -v.tolerance {
+		return d, ErrTimestampExpired
+	}
+	d.JobUID = h.Get(HeaderJobID)
+	msg := append([]byte(ts+"."+d.JobUID+"."), body...)
+	if err := v.verifySignatures(h, msg); err != nil {
+		return d, err
+	}
+	hash := sha256.Sum256(msg)
+	d.ID = hex.EncodeToString(hash[:])
+	d.Body = body
+	return d, nil
+}
+
+func (v *Verifier) verifySignatures(h http.Header, msg []byte) error {
+	signature, sig := h.Get(HeaderSignature), h.Get(HeaderSig)
+	if (len(v.secrets) == 0 || len(signature) == 0) && (len(v.publicKeys) == 0 || len(sig) == 0) {
+		return ErrMissingSignature
+	}
+	if len(v.secrets) > 0 && verifyHMAC(v.secrets, signature, msg) {
+		return nil
+	}
+	if key, ok := v.publicKeys[h.Get(HeaderKeyID)]; ok && verifyECDSA(key, sig, msg) {
+		return nil
+	}
+	return ErrInvalidSignature
+}
+
+// verifyHMAC reports whether any v1 entry of the header matches any secret
+func verifyHMAC(secrets []string, header string, msg []byte) bool {
+	for _, entry := range strings.Split(header, ",") {
+		scheme, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || scheme != "v1" {
+			continue
+		}
+		got, err := hex.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		for _, secret := range secrets {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(msg)
+			if hmac.Equal(got, mac.Sum(nil)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func verifyECDSA(key *ecdsa.PublicKey, header string, msg []byte) bool {
+	sig, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256(msg)
+	return ecdsa.VerifyASN1(key, hash[:], sig)
+}
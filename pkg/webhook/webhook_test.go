@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test"
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// signedHeader returns the headers of a request signed like hermeshooks
+// signs them
+func signedHeader(jobID string, ts time.Time, body []byte, secrets ...string) http.Header {
+	h := make(http.Header)
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	h.Set(HeaderTimestamp, unix)
+	h.Set(HeaderJobID, jobID)
+	var entries string
+	for i, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(unix + "." + jobID + "."))
+		mac.Write(body)
+		if i > 0 {
+			entries += ","
+		}
+		entries += "v1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	h.Set(HeaderSignature, entries)
+	return h
+}
+
+func newTestVerifier(t *testing.T, opts ...Option) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(append([]Option{WithSecrets(testSecret)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func TestDeliveryIDDiffersBetweenJobs(t *testing.T) {
+	v := newTestVerifier(t)
+	body := []byte(`{"remind":"me"}`)
+	d1, err := v.Verify(signedHeader("job-1", testNow, body, testSecret), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := v.Verify(signedHeader("job-2", testNow, body, testSecret), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d1.ID == d2.ID {
+		t.Fatal("jobs with the same payload and timestamp have the same delivery id")
+	}
+	if d1.JobUID != "job-1" {
+		t.Fatalf("unexpected job uid %q", d1.JobUID)
+	}
+}
+
+func TestVerifyRejectsChangedJobID(t *testing.T) {
+	v := newTestVerifier(t)
+	body := []byte(`{}`)
+	h := signedHeader("job-1", testNow, body, testSecret)
+	h.Set(HeaderJobID, "job-2")
+	if _, err := v.Verify(h, body); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	tests := []struct {
+		name    string
+		header  func() http.Header
+		body    []byte
+		wantErr error
+	}{
+		{"valid", func() http.Header {
+			return signedHeader("job-1", testNow, body, testSecret)
+		}, body, nil},
+		{"rotation with the new secret first", func() http.Header {
+			return signedHeader("job-1", testNow, body, "whsec_new", testSecret)
+		}, body, nil},
+		{"unknown secret", func() http.Header {
+			return signedHeader("job-1", testNow, body, "whsec_other")
+		}, body, ErrInvalidSignature},
+		{"changed body", func() http.Header {
+			return signedHeader("job-1", testNow, body, testSecret)
+		}, []byte(`{"a":2}`), ErrInvalidSignature},
+		{"unsupported scheme", func() http.Header {
+			h := signedHeader("job-1", testNow, body, testSecret)
+			h.Set(HeaderSignature, "v0"+h.Get(HeaderSignature)[2:])
+			return h
+		}, body, ErrInvalidSignature},
+		{"missing signature", func() http.Header {
+			h := signedHeader("job-1", testNow, body, testSecret)
+			h.Del(HeaderSignature)
+			return h
+		}, body, ErrMissingSignature},
+		{"invalid timestamp", func() http.Header {
+			h := signedHeader("job-1", testNow, body, testSecret)
+			h.Set(HeaderTimestamp, "yesterday")
+			return h
+		}, body, ErrInvalidTimestamp},
+		{"within the tolerance", func() http.Header {
+			return signedHeader("job-1", testNow.Add(-DefaultTolerance), body, testSecret)
+		}, body, nil},
+		{"too old", func() http.Header {
+			return signedHeader("job-1", testNow.Add(-DefaultTolerance-time.Second), body, testSecret)
+		}, body, ErrTimestampExpired},
+		{"too far in the future", func() http.Header {
+			return signedHeader("job-1", testNow.Add(DefaultTolerance+time.Second), body, testSecret)
+		}, body, ErrTimestampExpired},
+	}
+	v := newTestVerifier(t)
+	for _, tt := range tests {
+		_, err := v.Verify(tt.header(), tt.body)
+		if err != tt.wantErr {
+			t.Errorf("%s: Verify error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVerifyRotatedSecrets(t *testing.T) {
+	body := []byte(`{}`)
+	v := newTestVerifier(t, WithSecrets("whsec_old"))
+	for _, secret := range []string{testSecret, "whsec_old"} {
+		if _, err := v.Verify(signedHeader("job-1", testNow, body, secret), body); err != nil {
+			t.Errorf("request signed with %s: %v", secret, err)
+		}
+	}
+}
+
+func TestVerifyECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVerifier(WithPublicKeys(map[string]*ecdsa.PublicKey{"k1": &key.PublicKey}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+
+	body := []byte(`{"a":1}`)
+	sign := func(k *ecdsa.PrivateKey, kid string) http.Header {
+		h := signedHeader("job-1", testNow, body)
+		h.Del(HeaderSignature)
+		hash := sha256.Sum256([]byte(h.Get(HeaderTimestamp) + ".job-1." + string(body)))
+		sig, err := ecdsa.SignASN1(rand.Reader, k, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Set(HeaderSig, base64.StdEncoding.EncodeToString(sig))
+		h.Set(HeaderKeyID, kid)
+		return h
+	}
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr error
+	}{
+		{"valid", sign(key, "k1"), nil},
+		{"unknown key id", sign(key, "k2"), ErrInvalidSignature},
+		{"other key", sign(other, "k1"), ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		if _, err := v.Verify(tt.header, body); err != tt.wantErr {
+			t.Errorf("%s: Verify error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	h := make(http.Header)
+	h.Set(HeaderAttempt, "3")
+	h.Set(HeaderScheduledAt, "2024-05-01T11:59:00Z")
+	h.Set(HeaderPayloadSig, "sig")
+	m := ParseMetadata(h)
+	if m.Attempt != 3 || !m.ScheduledAt.Equal(testNow.Add(-time.Minute)) || m.PayloadSignature != "sig" {
+		t.Fatalf("unexpected metadata %+v", m)
+	}
+}